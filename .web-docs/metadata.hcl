@@ -13,4 +13,24 @@ integration {
     name = "Tencent Cloud Builder"
     slug = "cvm"
   }
+  component {
+    type = "builder"
+    name = "Tencent Cloud Chroot Builder"
+    slug = "chroot"
+  }
+  component {
+    type = "data-source"
+    name = "Tencent Cloud Image"
+    slug = "image"
+  }
+  component {
+    type = "post-processor"
+    name = "Tencent Cloud Import"
+    slug = "import"
+  }
+  component {
+    type = "post-processor"
+    name = "Tencent Cloud Export"
+    slug = "export"
+  }
 }
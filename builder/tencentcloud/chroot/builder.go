@@ -0,0 +1,282 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+//go:generate packer-sdc struct-markdown
+//go:generate packer-sdc mapstructure-to-hcl2 -type Config
+
+// Package chroot builds images from a cbs disk attached to the cvm Packer
+// runs on, provisioning it in a chroot instead of launching an instance.
+package chroot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+
+	"github.com/hashicorp/hcl/v2/hcldec"
+	"github.com/hashicorp/packer-plugin-sdk/chroot"
+	"github.com/hashicorp/packer-plugin-sdk/common"
+	"github.com/hashicorp/packer-plugin-sdk/multistep"
+	"github.com/hashicorp/packer-plugin-sdk/multistep/commonsteps"
+	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
+	"github.com/hashicorp/packer-plugin-sdk/template/config"
+	"github.com/hashicorp/packer-plugin-sdk/template/interpolate"
+
+	builder "github.com/hashicorp/packer-plugin-tencentcloud/builder/tencentcloud/cvm"
+)
+
+const BuilderId = "tencent.cloud.chroot"
+
+type Config struct {
+	common.PackerConfig              `mapstructure:",squash"`
+	builder.TencentCloudAccessConfig `mapstructure:",squash"`
+	builder.TencentCloudImageConfig  `mapstructure:",squash"`
+
+	// The id of the image the system disk is created from. The image must
+	// be compatible with the cvm Packer runs on, as the provisioners run
+	// with its kernel.
+	SourceImageId string `mapstructure:"source_image_id" required:"true"`
+	// Type of the cbs disk created from the source image, default is
+	// `CLOUD_PREMIUM`.
+	DiskType string `mapstructure:"disk_type" required:"false"`
+	// Size of the cbs disk created from the source image in GB, default is
+	// the size of the source image system disk. It can only be larger.
+	DiskSize int64 `mapstructure:"disk_size" required:"false"`
+	// The path to the device the cbs disk is attached as, it is found
+	// from the disk id if not set.
+	DevicePath string `mapstructure:"device_path" required:"false"`
+	// The partition number of the device containing the root filesystem,
+	// default is `1`. Set it to `0` to mount the whole device.
+	MountPartition string `mapstructure:"mount_partition" required:"false"`
+	// The path where the device is mounted, default is
+	// `/mnt/packer-tencentcloud-chroot/{{.Device}}`. This is a
+	// configuration template where the `.Device` variable is replaced
+	// with the name of the device.
+	MountPath string `mapstructure:"mount_path" required:"false"`
+	// Options passed to `mount -o` when mounting the device.
+	MountOptions []string `mapstructure:"mount_options" required:"false"`
+	// Commands run on the host before mounting the device. These are
+	// configuration templates where `.Device` is the device path and
+	// `.MountPath` the mount path.
+	PreMountCommands []string `mapstructure:"pre_mount_commands" required:"false"`
+	// Same as `pre_mount_commands`, but run after the device is mounted and
+	// before the extra mounts and files are set up.
+	PostMountCommands []string `mapstructure:"post_mount_commands" required:"false"`
+	// A list of mounts done within the chroot, each being a list of the
+	// filesystem type, the source and the path within the mount path.
+	// Default is to mount `/proc`, `/sys`, `/dev`, `/dev/pts` and
+	// `/proc/sys/fs/binfmt_misc`.
+	ChrootMounts [][]string `mapstructure:"chroot_mounts" required:"false"`
+	// Paths of files copied from the host into the chroot, default is
+	// `/etc/resolv.conf` so that the network works during provisioning.
+	CopyFiles []string `mapstructure:"copy_files" required:"false"`
+	// How to run the shell commands, such as `sudo {{.Command}}`. This is a
+	// configuration template where `.Command` is the command to run.
+	// Default is `{{.Command}}`.
+	CommandWrapper string `mapstructure:"command_wrapper" required:"false"`
+
+	ctx interpolate.Context
+}
+
+func (c *Config) GetContext() interpolate.Context {
+	return c.ctx
+}
+
+type wrappedCommandTemplate struct {
+	Command string
+}
+
+type Builder struct {
+	config Config
+	runner multistep.Runner
+}
+
+func (b *Builder) ConfigSpec() hcldec.ObjectSpec { return b.config.FlatMapstructure().HCL2Spec() }
+
+func (b *Builder) Prepare(raws ...interface{}) ([]string, []string, error) {
+	err := config.Decode(&b.config, &config.DecodeOpts{
+		PluginType:         BuilderId,
+		Interpolate:        true,
+		InterpolateContext: &b.config.ctx,
+		InterpolateFilter: &interpolate.RenderFilter{
+			Exclude: []string{
+				"command_wrapper",
+				"mount_path",
+				"pre_mount_commands",
+				"post_mount_commands",
+			},
+		},
+	}, raws...)
+	b.config.ctx.EnableEnv = true
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(b.config.ChrootMounts) == 0 {
+		b.config.ChrootMounts = [][]string{
+			{"proc", "proc", "/proc"},
+			{"sysfs", "sysfs", "/sys"},
+			{"bind", "/dev", "/dev"},
+			{"devpts", "devpts", "/dev/pts"},
+			{"binfmt_misc", "binfmt_misc", "/proc/sys/fs/binfmt_misc"},
+		}
+	}
+
+	// A nil copy_files means it was not set, an empty list disables it.
+	if b.config.CopyFiles == nil {
+		b.config.CopyFiles = []string{"/etc/resolv.conf"}
+	}
+
+	if b.config.CommandWrapper == "" {
+		b.config.CommandWrapper = "{{.Command}}"
+	}
+
+	if b.config.MountPath == "" {
+		b.config.MountPath = "/mnt/packer-tencentcloud-chroot/{{.Device}}"
+	}
+
+	if b.config.MountPartition == "" {
+		b.config.MountPartition = "1"
+	}
+
+	if b.config.DiskType == "" {
+		b.config.DiskType = "CLOUD_PREMIUM"
+	}
+
+	// Accumulate any errors
+	var errs *packersdk.MultiError
+	errs = packersdk.MultiErrorAppend(errs, b.config.TencentCloudAccessConfig.Prepare(&b.config.ctx)...)
+	errs = packersdk.MultiErrorAppend(errs, b.config.TencentCloudImageConfig.Prepare(&b.config.ctx)...)
+	errs = packersdk.MultiErrorAppend(errs, b.config.TencentCloudImageConfig.CheckRegions(b.config.Region)...)
+
+	if b.config.SourceImageId == "" {
+		errs = packersdk.MultiErrorAppend(errs, errors.New("source_image_id must be specified"))
+	} else if !builder.CheckResourceIdFormat("img", b.config.SourceImageId) {
+		errs = packersdk.MultiErrorAppend(errs, errors.New("source_image_id wrong format"))
+	}
+
+	validDiskType := false
+	for _, valid := range builder.ValidCBSType {
+		if b.config.DiskType == valid && valid != "LOCAL_BASIC" && valid != "LOCAL_SSD" {
+			validDiskType = true
+		}
+	}
+	if !validDiskType {
+		errs = packersdk.MultiErrorAppend(errs, fmt.Errorf("specified disk_type(%s) is invalid", b.config.DiskType))
+	}
+
+	if b.config.DiskSize < 0 {
+		errs = packersdk.MultiErrorAppend(errs, fmt.Errorf("specified disk_size(%d) is invalid", b.config.DiskSize))
+	}
+
+	for _, mounts := range b.config.ChrootMounts {
+		if len(mounts) != 3 {
+			errs = packersdk.MultiErrorAppend(errs, errors.New("each chroot_mounts entry must have three elements"))
+			break
+		}
+	}
+
+	if errs != nil && len(errs.Errors) > 0 {
+		return nil, nil, errs
+	}
+
+	packersdk.LogSecretFilter.Set(b.config.SecretId, b.config.SecretKey)
+
+	return nil, nil, nil
+}
+
+func (b *Builder) Run(ctx context.Context, ui packersdk.Ui, hook packersdk.Hook) (packersdk.Artifact, error) {
+	if runtime.GOOS != "linux" {
+		return nil, errors.New("The tencentcloud-chroot builder only works on Linux environments.")
+	}
+
+	cvmClient, err := builder.NewCvmClient(&b.config.TencentCloudAccessConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	cbsClient, err := builder.NewCbsClient(&b.config.TencentCloudAccessConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	wrappedCommand := func(command string) (string, error) {
+		ictx := b.config.ctx
+		ictx.Data = &wrappedCommandTemplate{Command: command}
+		return interpolate.Render(b.config.CommandWrapper, &ictx)
+	}
+
+	state := new(multistep.BasicStateBag)
+	state.Put("config", &b.config)
+	state.Put("cvm_client", cvmClient)
+	state.Put("cbs_client", cbsClient)
+	state.Put("wrappedCommand", common.CommandWrapper(wrappedCommand))
+	state.Put("hook", hook)
+	state.Put("ui", ui)
+
+	// Build the steps
+	steps := []multistep.Step{
+		&stepInstanceInfo{},
+		builder.NewStepPreValidate(b.config.ImageName),
+		&stepCheckSourceImage{
+			SourceImageId: b.config.SourceImageId,
+		},
+		&stepCreateDisk{
+			DiskType: b.config.DiskType,
+			DiskSize: b.config.DiskSize,
+		},
+		&stepAttachDisk{
+			DevicePath: b.config.DevicePath,
+		},
+		&chroot.StepPreMountCommands{
+			Commands: b.config.PreMountCommands,
+		},
+		&stepMountDevice{
+			MountOptions:   b.config.MountOptions,
+			MountPartition: b.config.MountPartition,
+		},
+		&chroot.StepPostMountCommands{
+			Commands: b.config.PostMountCommands,
+		},
+		&chroot.StepMountExtra{
+			ChrootMounts: b.config.ChrootMounts,
+		},
+		&chroot.StepCopyFiles{
+			Files: b.config.CopyFiles,
+		},
+		&chroot.StepChrootProvision{},
+		&chroot.StepEarlyCleanup{},
+		&stepCreateSnapshot{},
+		&stepRegisterImage{},
+		builder.NewStepCopyImage(&b.config.TencentCloudAccessConfig, &b.config.TencentCloudImageConfig),
+		builder.NewStepShareImage(b.config.ImageShareAccounts, b.config.RegionShareAccounts(),
+			&b.config.TencentCloudAccessConfig),
+	}
+
+	b.runner = commonsteps.NewRunner(steps, b.config.PackerConfig, ui)
+	b.runner.Run(ctx, state)
+
+	if rawErr, ok := state.GetOk("error"); ok {
+		return nil, rawErr.(error)
+	}
+
+	if _, ok := state.GetOk("image"); !ok {
+		return nil, nil
+	}
+
+	artifact := &builder.Artifact{
+		TencentCloudImages: state.Get("tencentcloudimages").(map[string]string),
+		BuilderIdValue:     BuilderId,
+		Client:             cvmClient,
+		AccessConfig:       &b.config.TencentCloudAccessConfig,
+		StateData: map[string]interface{}{
+			"generated_data": state.Get("generated_data"),
+			"zone":           state.Get("zone"),
+			"source_image":   state.Get("source_image"),
+			"image_tags":     b.config.ImageTags,
+		},
+	}
+
+	return artifact, nil
+}
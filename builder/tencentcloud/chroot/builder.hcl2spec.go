@@ -0,0 +1,120 @@
+// Code generated by "packer-sdc mapstructure-to-hcl2"; DO NOT EDIT.
+
+package chroot
+
+import (
+	"github.com/hashicorp/hcl/v2/hcldec"
+	"github.com/hashicorp/packer-plugin-tencentcloud/builder/tencentcloud/cvm"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// FlatConfig is an auto-generated flat version of Config.
+// Where the contents of a field with a `mapstructure:,squash` tag are bubbled up.
+type FlatConfig struct {
+	PackerBuildName          *string                                   `mapstructure:"packer_build_name" cty:"packer_build_name" hcl:"packer_build_name"`
+	PackerBuilderType        *string                                   `mapstructure:"packer_builder_type" cty:"packer_builder_type" hcl:"packer_builder_type"`
+	PackerCoreVersion        *string                                   `mapstructure:"packer_core_version" cty:"packer_core_version" hcl:"packer_core_version"`
+	PackerDebug              *bool                                     `mapstructure:"packer_debug" cty:"packer_debug" hcl:"packer_debug"`
+	PackerForce              *bool                                     `mapstructure:"packer_force" cty:"packer_force" hcl:"packer_force"`
+	PackerOnError            *string                                   `mapstructure:"packer_on_error" cty:"packer_on_error" hcl:"packer_on_error"`
+	PackerUserVars           map[string]string                         `mapstructure:"packer_user_variables" cty:"packer_user_variables" hcl:"packer_user_variables"`
+	PackerSensitiveVars      []string                                  `mapstructure:"packer_sensitive_variables" cty:"packer_sensitive_variables" hcl:"packer_sensitive_variables"`
+	SecretId                 *string                                   `mapstructure:"secret_id" required:"true" cty:"secret_id" hcl:"secret_id"`
+	SecretKey                *string                                   `mapstructure:"secret_key" required:"true" cty:"secret_key" hcl:"secret_key"`
+	Region                   *string                                   `mapstructure:"region" required:"true" cty:"region" hcl:"region"`
+	Zone                     *string                                   `mapstructure:"zone" required:"false" cty:"zone" hcl:"zone"`
+	Zones                    []string                                  `mapstructure:"zones" required:"false" cty:"zones" hcl:"zones"`
+	CvmEndpoint              *string                                   `mapstructure:"cvm_endpoint" required:"false" cty:"cvm_endpoint" hcl:"cvm_endpoint"`
+	VpcEndpoint              *string                                   `mapstructure:"vpc_endpoint" required:"false" cty:"vpc_endpoint" hcl:"vpc_endpoint"`
+	TatEndpoint              *string                                   `mapstructure:"tat_endpoint" required:"false" cty:"tat_endpoint" hcl:"tat_endpoint"`
+	CbsEndpoint              *string                                   `mapstructure:"cbs_endpoint" required:"false" cty:"cbs_endpoint" hcl:"cbs_endpoint"`
+	TagEndpoint              *string                                   `mapstructure:"tag_endpoint" required:"false" cty:"tag_endpoint" hcl:"tag_endpoint"`
+	StsEndpoint              *string                                   `mapstructure:"sts_endpoint" required:"false" cty:"sts_endpoint" hcl:"sts_endpoint"`
+	SecurityToken            *string                                   `mapstructure:"security_token" required:"false" cty:"security_token" hcl:"security_token"`
+	AssumeRole               *cvm.FlatTencentCloudAccessRole           `mapstructure:"assume_role" required:"false" cty:"assume_role" hcl:"assume_role"`
+	Profile                  *string                                   `mapstructure:"profile" required:"false" cty:"profile" hcl:"profile"`
+	SharedCredentialsDir     *string                                   `mapstructure:"shared_credentials_dir" required:"false" cty:"shared_credentials_dir" hcl:"shared_credentials_dir"`
+	ImageName                *string                                   `mapstructure:"image_name" required:"true" cty:"image_name" hcl:"image_name"`
+	ImageDescription         *string                                   `mapstructure:"image_description" required:"false" cty:"image_description" hcl:"image_description"`
+	ForcePoweroff            *bool                                     `mapstructure:"force_poweroff" required:"false" cty:"force_poweroff" hcl:"force_poweroff"`
+	Sysprep                  *bool                                     `mapstructure:"sysprep" required:"false" cty:"sysprep" hcl:"sysprep"`
+	ImageCopyRegions         []string                                  `mapstructure:"image_copy_regions" required:"false" cty:"image_copy_regions" hcl:"image_copy_regions"`
+	ImageCopyAllowPartial    *bool                                     `mapstructure:"image_copy_allow_partial" required:"false" cty:"image_copy_allow_partial" hcl:"image_copy_allow_partial"`
+	ImageRegionOverrides     []cvm.FlattencentCloudRegionImage         `mapstructure:"image_region_overrides" required:"false" cty:"image_region_overrides" hcl:"image_region_overrides"`
+	ImageShareAccounts       []string                                  `mapstructure:"image_share_accounts" required:"false" cty:"image_share_accounts" hcl:"image_share_accounts"`
+	ImageRegionShareAccounts []cvm.FlattencentCloudRegionShareAccounts `mapstructure:"image_region_share_accounts" required:"false" cty:"image_region_share_accounts" hcl:"image_region_share_accounts"`
+	ImageTags                map[string]string                         `mapstructure:"image_tags" required:"false" cty:"image_tags" hcl:"image_tags"`
+	SourceImageId            *string                                   `mapstructure:"source_image_id" required:"true" cty:"source_image_id" hcl:"source_image_id"`
+	DiskType                 *string                                   `mapstructure:"disk_type" required:"false" cty:"disk_type" hcl:"disk_type"`
+	DiskSize                 *int64                                    `mapstructure:"disk_size" required:"false" cty:"disk_size" hcl:"disk_size"`
+	DevicePath               *string                                   `mapstructure:"device_path" required:"false" cty:"device_path" hcl:"device_path"`
+	MountPartition           *string                                   `mapstructure:"mount_partition" required:"false" cty:"mount_partition" hcl:"mount_partition"`
+	MountPath                *string                                   `mapstructure:"mount_path" required:"false" cty:"mount_path" hcl:"mount_path"`
+	MountOptions             []string                                  `mapstructure:"mount_options" required:"false" cty:"mount_options" hcl:"mount_options"`
+	PreMountCommands         []string                                  `mapstructure:"pre_mount_commands" required:"false" cty:"pre_mount_commands" hcl:"pre_mount_commands"`
+	PostMountCommands        []string                                  `mapstructure:"post_mount_commands" required:"false" cty:"post_mount_commands" hcl:"post_mount_commands"`
+	ChrootMounts             [][]string                                `mapstructure:"chroot_mounts" required:"false" cty:"chroot_mounts" hcl:"chroot_mounts"`
+	CopyFiles                []string                                  `mapstructure:"copy_files" required:"false" cty:"copy_files" hcl:"copy_files"`
+	CommandWrapper           *string                                   `mapstructure:"command_wrapper" required:"false" cty:"command_wrapper" hcl:"command_wrapper"`
+}
+
+// FlatMapstructure returns a new FlatConfig.
+// FlatConfig is an auto-generated flat version of Config.
+// Where the contents a fields with a `mapstructure:,squash` tag are bubbled up.
+func (*Config) FlatMapstructure() interface{ HCL2Spec() map[string]hcldec.Spec } {
+	return new(FlatConfig)
+}
+
+// HCL2Spec returns the hcl spec of a Config.
+// This spec is used by HCL to read the fields of Config.
+// The decoded values from this spec will then be applied to a FlatConfig.
+func (*FlatConfig) HCL2Spec() map[string]hcldec.Spec {
+	s := map[string]hcldec.Spec{
+		"packer_build_name":           &hcldec.AttrSpec{Name: "packer_build_name", Type: cty.String, Required: false},
+		"packer_builder_type":         &hcldec.AttrSpec{Name: "packer_builder_type", Type: cty.String, Required: false},
+		"packer_core_version":         &hcldec.AttrSpec{Name: "packer_core_version", Type: cty.String, Required: false},
+		"packer_debug":                &hcldec.AttrSpec{Name: "packer_debug", Type: cty.Bool, Required: false},
+		"packer_force":                &hcldec.AttrSpec{Name: "packer_force", Type: cty.Bool, Required: false},
+		"packer_on_error":             &hcldec.AttrSpec{Name: "packer_on_error", Type: cty.String, Required: false},
+		"packer_user_variables":       &hcldec.AttrSpec{Name: "packer_user_variables", Type: cty.Map(cty.String), Required: false},
+		"packer_sensitive_variables":  &hcldec.AttrSpec{Name: "packer_sensitive_variables", Type: cty.List(cty.String), Required: false},
+		"secret_id":                   &hcldec.AttrSpec{Name: "secret_id", Type: cty.String, Required: false},
+		"secret_key":                  &hcldec.AttrSpec{Name: "secret_key", Type: cty.String, Required: false},
+		"region":                      &hcldec.AttrSpec{Name: "region", Type: cty.String, Required: false},
+		"zone":                        &hcldec.AttrSpec{Name: "zone", Type: cty.String, Required: false},
+		"zones":                       &hcldec.AttrSpec{Name: "zones", Type: cty.List(cty.String), Required: false},
+		"cvm_endpoint":                &hcldec.AttrSpec{Name: "cvm_endpoint", Type: cty.String, Required: false},
+		"vpc_endpoint":                &hcldec.AttrSpec{Name: "vpc_endpoint", Type: cty.String, Required: false},
+		"tat_endpoint":                &hcldec.AttrSpec{Name: "tat_endpoint", Type: cty.String, Required: false},
+		"cbs_endpoint":                &hcldec.AttrSpec{Name: "cbs_endpoint", Type: cty.String, Required: false},
+		"tag_endpoint":                &hcldec.AttrSpec{Name: "tag_endpoint", Type: cty.String, Required: false},
+		"sts_endpoint":                &hcldec.AttrSpec{Name: "sts_endpoint", Type: cty.String, Required: false},
+		"security_token":              &hcldec.AttrSpec{Name: "security_token", Type: cty.String, Required: false},
+		"assume_role":                 &hcldec.BlockSpec{TypeName: "assume_role", Nested: hcldec.ObjectSpec((*cvm.FlatTencentCloudAccessRole)(nil).HCL2Spec())},
+		"profile":                     &hcldec.AttrSpec{Name: "profile", Type: cty.String, Required: false},
+		"shared_credentials_dir":      &hcldec.AttrSpec{Name: "shared_credentials_dir", Type: cty.String, Required: false},
+		"image_name":                  &hcldec.AttrSpec{Name: "image_name", Type: cty.String, Required: false},
+		"image_description":           &hcldec.AttrSpec{Name: "image_description", Type: cty.String, Required: false},
+		"force_poweroff":              &hcldec.AttrSpec{Name: "force_poweroff", Type: cty.Bool, Required: false},
+		"sysprep":                     &hcldec.AttrSpec{Name: "sysprep", Type: cty.Bool, Required: false},
+		"image_copy_regions":          &hcldec.AttrSpec{Name: "image_copy_regions", Type: cty.List(cty.String), Required: false},
+		"image_copy_allow_partial":    &hcldec.AttrSpec{Name: "image_copy_allow_partial", Type: cty.Bool, Required: false},
+		"image_region_overrides":      &hcldec.BlockListSpec{TypeName: "image_region_overrides", Nested: hcldec.ObjectSpec((*cvm.FlattencentCloudRegionImage)(nil).HCL2Spec())},
+		"image_share_accounts":        &hcldec.AttrSpec{Name: "image_share_accounts", Type: cty.List(cty.String), Required: false},
+		"image_region_share_accounts": &hcldec.BlockListSpec{TypeName: "image_region_share_accounts", Nested: hcldec.ObjectSpec((*cvm.FlattencentCloudRegionShareAccounts)(nil).HCL2Spec())},
+		"image_tags":                  &hcldec.AttrSpec{Name: "image_tags", Type: cty.Map(cty.String), Required: false},
+		"source_image_id":             &hcldec.AttrSpec{Name: "source_image_id", Type: cty.String, Required: false},
+		"disk_type":                   &hcldec.AttrSpec{Name: "disk_type", Type: cty.String, Required: false},
+		"disk_size":                   &hcldec.AttrSpec{Name: "disk_size", Type: cty.Number, Required: false},
+		"device_path":                 &hcldec.AttrSpec{Name: "device_path", Type: cty.String, Required: false},
+		"mount_partition":             &hcldec.AttrSpec{Name: "mount_partition", Type: cty.String, Required: false},
+		"mount_path":                  &hcldec.AttrSpec{Name: "mount_path", Type: cty.String, Required: false},
+		"mount_options":               &hcldec.AttrSpec{Name: "mount_options", Type: cty.List(cty.String), Required: false},
+		"pre_mount_commands":          &hcldec.AttrSpec{Name: "pre_mount_commands", Type: cty.List(cty.String), Required: false},
+		"post_mount_commands":         &hcldec.AttrSpec{Name: "post_mount_commands", Type: cty.List(cty.String), Required: false},
+		"chroot_mounts":               &hcldec.AttrSpec{Name: "chroot_mounts", Type: cty.List(cty.List(cty.String)), Required: false},
+		"copy_files":                  &hcldec.AttrSpec{Name: "copy_files", Type: cty.List(cty.String), Required: false},
+		"command_wrapper":             &hcldec.AttrSpec{Name: "command_wrapper", Type: cty.String, Required: false},
+	}
+	return s
+}
@@ -0,0 +1,88 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package chroot
+
+import (
+	"testing"
+)
+
+func testConfig() map[string]interface{} {
+	return map[string]interface{}{
+		"secret_id":       "secret-id",
+		"secret_key":      "secret-key",
+		"region":          "ap-guangzhou",
+		"source_image_id": "img-qwer1234",
+		"image_name":      "packer-chroot",
+	}
+}
+
+func TestBuilder_Prepare(t *testing.T) {
+	var b Builder
+	if _, _, err := b.Prepare(testConfig()); err != nil {
+		t.Fatalf("shouldn't have err: %v", err)
+	}
+
+	if len(b.config.ChrootMounts) != 5 {
+		t.Fatalf("invalid default chroot_mounts: %v", b.config.ChrootMounts)
+	}
+	if len(b.config.CopyFiles) != 1 || b.config.CopyFiles[0] != "/etc/resolv.conf" {
+		t.Fatalf("invalid default copy_files: %v", b.config.CopyFiles)
+	}
+	if b.config.MountPartition != "1" {
+		t.Fatalf("invalid default mount_partition: %s", b.config.MountPartition)
+	}
+	if b.config.DiskType != "CLOUD_PREMIUM" {
+		t.Fatalf("invalid default disk_type: %s", b.config.DiskType)
+	}
+	if b.config.CommandWrapper != "{{.Command}}" {
+		t.Fatalf("invalid default command_wrapper: %s", b.config.CommandWrapper)
+	}
+}
+
+func TestBuilder_Prepare_CopyFiles(t *testing.T) {
+	var b Builder
+	config := testConfig()
+	config["copy_files"] = []string{}
+	if _, _, err := b.Prepare(config); err != nil {
+		t.Fatalf("shouldn't have err: %v", err)
+	}
+
+	if len(b.config.CopyFiles) != 0 {
+		t.Fatalf("copy_files should be disabled: %v", b.config.CopyFiles)
+	}
+}
+
+func TestBuilder_Prepare_Invalid(t *testing.T) {
+	cases := map[string]interface{}{
+		"source_image_id": "ins-qwer1234",
+		"disk_type":       "LOCAL_BASIC",
+		"disk_size":       -1,
+		"chroot_mounts":   [][]string{{"proc", "/proc"}},
+	}
+
+	for key, value := range cases {
+		var b Builder
+		config := testConfig()
+		config[key] = value
+		if _, _, err := b.Prepare(config); err == nil {
+			t.Fatalf("should have err with %s: %v", key, value)
+		}
+	}
+
+	var b Builder
+	config := testConfig()
+	delete(config, "source_image_id")
+	if _, _, err := b.Prepare(config); err == nil {
+		t.Fatal("should have err without source_image_id")
+	}
+}
+
+func TestPartitionPath(t *testing.T) {
+	if p := partitionPath("/dev/vdb", "1"); p != "/dev/vdb1" {
+		t.Fatalf("invalid partition path: %s", p)
+	}
+	if p := partitionPath("/dev/nvme1n1", "1"); p != "/dev/nvme1n1p1" {
+		t.Fatalf("invalid partition path: %s", p)
+	}
+}
@@ -0,0 +1,110 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package chroot
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/hashicorp/packer-plugin-sdk/multistep"
+
+	builder "github.com/hashicorp/packer-plugin-tencentcloud/builder/tencentcloud/cvm"
+)
+
+// stepAttachDisk attaches the cbs disk to the current instance.
+//
+// Produces:
+//
+//	device string - The path to the attached device
+//	attach_cleanup CleanupFunc - To perform early cleanup
+type stepAttachDisk struct {
+	DevicePath string
+
+	diskId     string
+	instanceId string
+}
+
+func (s *stepAttachDisk) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
+	client := state.Get("cbs_client").(*builder.CbsClient)
+	diskId := state.Get("disk_id").(string)
+	instanceId := state.Get("instance_id").(string)
+
+	builder.Say(state, diskId, "Trying to attach cbs disk")
+
+	if err := client.AttachDisk(ctx, diskId, instanceId); err != nil {
+		return builder.Halt(state, err, "Failed to attach cbs disk")
+	}
+	s.diskId = diskId
+	s.instanceId = instanceId
+
+	builder.Message(state, "Waiting for cbs disk attached", "")
+	if err := client.WaitForDisk(ctx, diskId, "ATTACHED", 10*time.Minute); err != nil {
+		return builder.Halt(state, err, "Failed to wait for cbs disk attached")
+	}
+
+	device := s.DevicePath
+	if device == "" {
+		// The serial of virtio disks is the cbs disk id.
+		var err error
+		device, err = waitForDevice(ctx, "/dev/disk/by-id/virtio-"+diskId, 5*time.Minute)
+		if err != nil {
+			return builder.Halt(state, err, "Failed to find device of cbs disk")
+		}
+	}
+
+	state.Put("device", device)
+	state.Put("attach_cleanup", s)
+	builder.Message(state, device, "Cbs disk attached as")
+
+	return multistep.ActionContinue
+}
+
+func (s *stepAttachDisk) Cleanup(state multistep.StateBag) {
+	if err := s.CleanupFunc(state); err != nil {
+		builder.Error(state, err, fmt.Sprintf("Failed to detach cbs disk(%s), please detach it manually", s.diskId))
+	}
+}
+
+func (s *stepAttachDisk) CleanupFunc(state multistep.StateBag) error {
+	if s.diskId == "" {
+		return nil
+	}
+
+	ctx := context.TODO()
+	client := state.Get("cbs_client").(*builder.CbsClient)
+
+	builder.SayClean(state, "cbs disk attachment")
+
+	if err := client.DetachDisk(ctx, s.diskId, s.instanceId); err != nil {
+		return err
+	}
+	if err := client.WaitForDisk(ctx, s.diskId, "UNATTACHED", 10*time.Minute); err != nil {
+		return err
+	}
+
+	s.diskId = ""
+	return nil
+}
+
+// waitForDevice waits for the device link to appear and returns the device
+// it points to.
+func waitForDevice(ctx context.Context, link string, timeout time.Duration) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	for {
+		if _, err := os.Stat(link); err == nil {
+			return filepath.EvalSymlinks(link)
+		}
+
+		select {
+		case <-ctx.Done():
+			return "", fmt.Errorf("device %s not found after %s", link, timeout)
+		case <-time.After(time.Second):
+		}
+	}
+}
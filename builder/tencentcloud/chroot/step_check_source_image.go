@@ -0,0 +1,54 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package chroot
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/packer-plugin-sdk/multistep"
+	cvm "github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/cvm/v20170312"
+
+	builder "github.com/hashicorp/packer-plugin-tencentcloud/builder/tencentcloud/cvm"
+)
+
+type stepCheckSourceImage struct {
+	SourceImageId string
+}
+
+func (s *stepCheckSourceImage) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
+	client := state.Get("cvm_client").(*cvm.Client)
+
+	builder.Say(state, s.SourceImageId, "Trying to check source image")
+
+	req := cvm.NewDescribeImagesRequest()
+	req.ImageIds = []*string{&s.SourceImageId}
+	images, err := builder.GetImages(ctx, client, req)
+	if err != nil {
+		return builder.Halt(state, err, "Failed to get source image info")
+	}
+	if len(images) == 0 {
+		return builder.Halt(state, fmt.Errorf("No image found with id(%s)", s.SourceImageId), "")
+	}
+
+	image := images[0]
+	var snapshot *cvm.Snapshot
+	for _, s := range image.SnapshotSet {
+		if s.DiskUsage != nil && *s.DiskUsage == "SYSTEM_DISK" {
+			snapshot = s
+			break
+		}
+	}
+	if snapshot == nil {
+		return builder.Halt(state, fmt.Errorf("Image(%s) has no system disk snapshot", s.SourceImageId), "")
+	}
+
+	state.Put("source_image", image)
+	state.Put("source_snapshot", snapshot)
+	builder.Message(state, *image.ImageName, "Image found")
+
+	return multistep.ActionContinue
+}
+
+func (s *stepCheckSourceImage) Cleanup(multistep.StateBag) {}
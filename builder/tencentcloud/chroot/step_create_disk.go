@@ -0,0 +1,72 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package chroot
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/packer-plugin-sdk/multistep"
+	cvm "github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/cvm/v20170312"
+
+	builder "github.com/hashicorp/packer-plugin-tencentcloud/builder/tencentcloud/cvm"
+)
+
+type stepCreateDisk struct {
+	DiskType string
+	DiskSize int64
+
+	diskId string
+}
+
+func (s *stepCreateDisk) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
+	client := state.Get("cbs_client").(*builder.CbsClient)
+	config := state.Get("config").(*Config)
+	zone := state.Get("zone").(string)
+	snapshot := state.Get("source_snapshot").(*cvm.Snapshot)
+
+	size := *snapshot.DiskSize
+	if s.DiskSize > 0 {
+		if s.DiskSize < size {
+			return builder.Halt(state, fmt.Errorf("disk_size(%d) is smaller than the source image system disk(%d)",
+				s.DiskSize, size), "")
+		}
+		size = s.DiskSize
+	}
+
+	builder.Say(state, *snapshot.SnapshotId, "Trying to create a cbs disk from snapshot")
+
+	diskId, err := client.CreateDisk(ctx, zone, s.DiskType, "packer-"+config.PackerBuildName,
+		*snapshot.SnapshotId, uint64(size))
+	if err != nil {
+		return builder.Halt(state, err, "Failed to create cbs disk")
+	}
+	s.diskId = diskId
+
+	builder.Message(state, "Waiting for cbs disk ready", "")
+	if err := client.WaitForDisk(ctx, diskId, "UNATTACHED", 10*time.Minute); err != nil {
+		return builder.Halt(state, err, "Failed to wait for cbs disk ready")
+	}
+
+	state.Put("disk_id", diskId)
+	builder.Message(state, diskId, "Cbs disk created")
+
+	return multistep.ActionContinue
+}
+
+func (s *stepCreateDisk) Cleanup(state multistep.StateBag) {
+	if s.diskId == "" {
+		return
+	}
+
+	ctx := context.TODO()
+	client := state.Get("cbs_client").(*builder.CbsClient)
+
+	builder.SayClean(state, "cbs disk")
+
+	if err := client.TerminateDisk(ctx, s.diskId); err != nil {
+		builder.Error(state, err, fmt.Sprintf("Failed to terminate cbs disk(%s), please delete it manually", s.diskId))
+	}
+}
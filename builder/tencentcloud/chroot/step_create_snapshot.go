@@ -0,0 +1,86 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package chroot
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/packer-plugin-sdk/multistep"
+
+	builder "github.com/hashicorp/packer-plugin-tencentcloud/builder/tencentcloud/cvm"
+)
+
+type stepCreateSnapshot struct {
+	snapshotId string
+}
+
+func (s *stepCreateSnapshot) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
+	client := state.Get("cbs_client").(*builder.CbsClient)
+	config := state.Get("config").(*Config)
+	diskId := state.Get("disk_id").(string)
+
+	builder.Say(state, diskId, "Trying to create a snapshot of cbs disk")
+
+	snapshotId, err := client.CreateSnapshot(ctx, diskId, config.ImageName)
+	if err != nil {
+		return builder.Halt(state, err, "Failed to create snapshot")
+	}
+	s.snapshotId = snapshotId
+
+	builder.Message(state, "Waiting for snapshot ready", "")
+	if err := s.waitForSnapshot(ctx, client, time.Hour); err != nil {
+		return builder.Halt(state, err, "Failed to wait for snapshot ready")
+	}
+
+	state.Put("snapshot_id", snapshotId)
+	builder.Message(state, snapshotId, "Snapshot created")
+
+	return multistep.ActionContinue
+}
+
+func (s *stepCreateSnapshot) waitForSnapshot(ctx context.Context, client *builder.CbsClient, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		snapshot, err := client.DescribeSnapshot(ctx, s.snapshotId)
+		if err != nil {
+			return err
+		}
+		if snapshot.SnapshotState != nil {
+			switch *snapshot.SnapshotState {
+			case "NORMAL":
+				return nil
+			case "CREATING":
+			default:
+				return fmt.Errorf("snapshot(%s) is %s", s.snapshotId, *snapshot.SnapshotState)
+			}
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("wait snapshot(%s) ready timeout", s.snapshotId)
+		}
+		time.Sleep(builder.DefaultWaitForInterval * time.Second)
+	}
+}
+
+func (s *stepCreateSnapshot) Cleanup(state multistep.StateBag) {
+	if s.snapshotId == "" {
+		return
+	}
+
+	_, cancelled := state.GetOk(multistep.StateCancelled)
+	_, halted := state.GetOk(multistep.StateHalted)
+	if !cancelled && !halted {
+		return
+	}
+
+	ctx := context.TODO()
+	client := state.Get("cbs_client").(*builder.CbsClient)
+
+	builder.SayClean(state, "snapshot")
+
+	if err := client.DeleteSnapshot(ctx, s.snapshotId); err != nil {
+		builder.Error(state, err, fmt.Sprintf("Failed to delete snapshot(%s), please delete it manually", s.snapshotId))
+	}
+}
@@ -0,0 +1,38 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package chroot
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/packer-plugin-sdk/multistep"
+
+	builder "github.com/hashicorp/packer-plugin-tencentcloud/builder/tencentcloud/cvm"
+)
+
+// stepInstanceInfo finds the cvm Packer runs on, the disk is attached to it.
+type stepInstanceInfo struct{}
+
+func (s *stepInstanceInfo) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
+	builder.Say(state, "metadata", "Trying to get current instance info from")
+
+	instanceId, err := getMetadata(ctx, "instance-id")
+	if err != nil {
+		return builder.Halt(state, err, "Failed to get instance id, is Packer running on a cvm")
+	}
+
+	zone, err := getMetadata(ctx, "placement/zone")
+	if err != nil {
+		return builder.Halt(state, err, "Failed to get instance zone")
+	}
+
+	state.Put("instance_id", instanceId)
+	state.Put("zone", zone)
+	builder.Message(state, fmt.Sprintf("%s in %s", instanceId, zone), "Current instance")
+
+	return multistep.ActionContinue
+}
+
+func (s *stepInstanceInfo) Cleanup(multistep.StateBag) {}
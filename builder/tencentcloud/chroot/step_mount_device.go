@@ -0,0 +1,132 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package chroot
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hashicorp/packer-plugin-sdk/common"
+	"github.com/hashicorp/packer-plugin-sdk/multistep"
+	"github.com/hashicorp/packer-plugin-sdk/template/interpolate"
+
+	builder "github.com/hashicorp/packer-plugin-tencentcloud/builder/tencentcloud/cvm"
+)
+
+type mountPathData struct {
+	Device string
+}
+
+// stepMountDevice mounts the attached device.
+//
+// Produces:
+//
+//	mount_path string - The location where the device was mounted
+//	mount_device_cleanup CleanupFunc - To perform early cleanup
+type stepMountDevice struct {
+	MountOptions   []string
+	MountPartition string
+
+	mountPath string
+}
+
+func (s *stepMountDevice) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
+	config := state.Get("config").(*Config)
+	device := state.Get("device").(string)
+	wrappedCommand := state.Get("wrappedCommand").(common.CommandWrapper)
+
+	ictx := config.ctx
+	ictx.Data = &mountPathData{Device: filepath.Base(device)}
+	mountPath, err := interpolate.Render(config.MountPath, &ictx)
+	if err != nil {
+		return builder.Halt(state, err, "Failed to render mount path")
+	}
+
+	mountPath, err = filepath.Abs(mountPath)
+	if err != nil {
+		return builder.Halt(state, err, "Failed to prepare mount directory")
+	}
+
+	log.Printf("Mount path: %s", mountPath)
+	if err := os.MkdirAll(mountPath, 0755); err != nil {
+		return builder.Halt(state, err, "Failed to create mount directory")
+	}
+
+	deviceMount := device
+	if s.MountPartition != "0" {
+		deviceMount = partitionPath(device, s.MountPartition)
+	}
+
+	builder.Say(state, deviceMount, "Trying to mount device")
+
+	opts := ""
+	if len(s.MountOptions) > 0 {
+		opts = "-o " + strings.Join(s.MountOptions, " -o ")
+	}
+	mountCommand, err := wrappedCommand(fmt.Sprintf("mount %s %s %s", opts, deviceMount, mountPath))
+	if err != nil {
+		return builder.Halt(state, err, "Failed to create mount command")
+	}
+	log.Printf("[DEBUG] (step mount) mount command is %s", mountCommand)
+
+	var stderr bytes.Buffer
+	cmd := common.ShellCommand(mountCommand)
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return builder.Halt(state, fmt.Errorf("%s: %s", err, strings.TrimSpace(stderr.String())),
+			"Failed to mount device")
+	}
+
+	s.mountPath = mountPath
+	state.Put("mount_path", s.mountPath)
+	state.Put("mount_device_cleanup", s)
+	builder.Message(state, mountPath, "Device mounted at")
+
+	return multistep.ActionContinue
+}
+
+func (s *stepMountDevice) Cleanup(state multistep.StateBag) {
+	if err := s.CleanupFunc(state); err != nil {
+		builder.Error(state, err, fmt.Sprintf("Failed to unmount %s", s.mountPath))
+	}
+}
+
+func (s *stepMountDevice) CleanupFunc(state multistep.StateBag) error {
+	if s.mountPath == "" {
+		return nil
+	}
+
+	wrappedCommand := state.Get("wrappedCommand").(common.CommandWrapper)
+
+	builder.SayClean(state, "device mount")
+
+	unmountCommand, err := wrappedCommand(fmt.Sprintf("umount %s", s.mountPath))
+	if err != nil {
+		return fmt.Errorf("error creating unmount command: %s", err)
+	}
+
+	var stderr bytes.Buffer
+	cmd := common.ShellCommand(unmountCommand)
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("error unmounting root device: %s: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	s.mountPath = ""
+	return nil
+}
+
+// partitionPath returns the path of the partition of the device, nvme
+// devices separate the partition number with a "p".
+func partitionPath(device, partition string) string {
+	if last := device[len(device)-1]; last >= '0' && last <= '9' {
+		return device + "p" + partition
+	}
+	return device + partition
+}
@@ -0,0 +1,112 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package chroot
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/packer-plugin-sdk/multistep"
+	cvm "github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/cvm/v20170312"
+
+	builder "github.com/hashicorp/packer-plugin-tencentcloud/builder/tencentcloud/cvm"
+)
+
+// stepRegisterImage creates the image from the snapshot of the cbs disk.
+type stepRegisterImage struct {
+	imageId string
+}
+
+func (s *stepRegisterImage) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
+	client := state.Get("cvm_client").(*cvm.Client)
+	config := state.Get("config").(*Config)
+	snapshotId := state.Get("snapshot_id").(string)
+
+	builder.Say(state, config.ImageName, "Trying to create a new image")
+
+	req := cvm.NewCreateImageRequest()
+	req.ImageName = &config.ImageName
+	req.ImageDescription = &config.ImageDescription
+	req.SnapshotIds = []*string{&snapshotId}
+
+	var tags []*cvm.Tag
+	for k, v := range config.ImageTags {
+		k := k
+		v := v
+		tags = append(tags, &cvm.Tag{
+			Key:   &k,
+			Value: &v,
+		})
+	}
+
+	resourceType := "image"
+	if len(tags) > 0 {
+		req.TagSpecification = []*cvm.TagSpecification{
+			{
+				ResourceType: &resourceType,
+				Tags:         tags,
+			},
+		}
+	}
+
+	err := builder.Retry(ctx, func(ctx context.Context) error {
+		_, e := client.CreateImage(req)
+		return e
+	})
+	if err != nil {
+		return builder.Halt(state, err, "Failed to create image")
+	}
+
+	builder.Message(state, "Waiting for image ready", "")
+	err = builder.WaitForImageReady(ctx, client, config.ImageName, "NORMAL", 3600)
+	if err != nil {
+		return builder.Halt(state, err, "Failed to wait for image ready")
+	}
+
+	image, err := builder.GetImageByName(ctx, client, config.ImageName)
+	if err != nil {
+		return builder.Halt(state, err, "Failed to get image")
+	}
+
+	if image == nil {
+		return builder.Halt(state, fmt.Errorf("No image return"), "Failed to create image")
+	}
+
+	s.imageId = *image.ImageId
+	state.Put("image", image)
+	builder.Message(state, s.imageId, "Image created")
+
+	tencentCloudImages := make(map[string]string)
+	tencentCloudImages[config.Region] = s.imageId
+	state.Put("tencentcloudimages", tencentCloudImages)
+
+	return multistep.ActionContinue
+}
+
+func (s *stepRegisterImage) Cleanup(state multistep.StateBag) {
+	if s.imageId == "" {
+		return
+	}
+
+	_, cancelled := state.GetOk(multistep.StateCancelled)
+	_, halted := state.GetOk(multistep.StateHalted)
+	if !cancelled && !halted {
+		return
+	}
+
+	ctx := context.TODO()
+	client := state.Get("cvm_client").(*cvm.Client)
+
+	builder.SayClean(state, "image")
+
+	req := cvm.NewDeleteImagesRequest()
+	req.ImageIds = []*string{&s.imageId}
+	err := builder.Retry(ctx, func(ctx context.Context) error {
+		_, e := client.DeleteImages(req)
+		return e
+	})
+	if err != nil {
+		builder.Error(state, err, fmt.Sprintf("Failed to delete image(%s), please delete it manually", s.imageId))
+	}
+}
@@ -83,14 +83,32 @@ type TencentCloudAccessConfig struct {
 	Region string `mapstructure:"region" required:"true"`
 	// The zone where your cvm will be launch. You should
 	// reference [Region and Zone](https://intl.cloud.tencent.com/document/product/213/6091)
-	// for parameter taking.
-	Zone string `mapstructure:"zone" required:"true"`
+	// for parameter taking. If neither `zone` nor `zones` is set, the zones
+	// of the region offering the instance types are used.
+	Zone string `mapstructure:"zone" required:"false"`
+	// Additional zones your cvm may be launched in. Zones are tried in
+	// order, after `zone`, when the instance types are sold out. Zones not
+	// offering any of the instance types are skipped.
+	Zones []string `mapstructure:"zones" required:"false"`
 	// The endpoint you want to reach the cloud endpoint,
 	// if tce cloud you should set a tce cvm endpoint.
 	CvmEndpoint string `mapstructure:"cvm_endpoint" required:"false"`
 	// The endpoint you want to reach the cloud endpoint,
 	// if tce cloud you should set a tce vpc endpoint.
 	VpcEndpoint string `mapstructure:"vpc_endpoint" required:"false"`
+	// The endpoint you want to reach the tat api used by the `tat`
+	// communicator, if tce cloud you should set a tce tat endpoint.
+	TatEndpoint string `mapstructure:"tat_endpoint" required:"false"`
+	// The endpoint you want to reach the cbs api used by the chroot
+	// builder, if tce cloud you should set a tce cbs endpoint.
+	CbsEndpoint string `mapstructure:"cbs_endpoint" required:"false"`
+	// The endpoint you want to reach the tag api used to tag the copied
+	// images, if tce cloud you should set a tce tag endpoint.
+	TagEndpoint string `mapstructure:"tag_endpoint" required:"false"`
+	// The endpoint you want to reach the sts api used to look up the
+	// account the copied images are tagged in, if tce cloud you should set
+	// a tce sts endpoint.
+	StsEndpoint string `mapstructure:"sts_endpoint" required:"false"`
 	// The region validation can be skipped if this value is true, the default
 	// value is false.
 	skipValidation bool
@@ -143,10 +161,6 @@ func (cf *TencentCloudAccessConfig) Client() (*cvm.Client, *vpc.Client, error) {
 		return nil, nil, err
 	}
 
-	if cf.Zone == "" {
-		return nil, nil, fmt.Errorf("parameter zone must be set")
-	}
-
 	if cvm_client, err = NewCvmClient(cf); err != nil {
 		return nil, nil, err
 	}
@@ -165,13 +179,24 @@ func (cf *TencentCloudAccessConfig) Client() (*cvm.Client, *vpc.Client, error) {
 		return nil, nil, err
 	}
 
+	validZones := make(map[string]struct{}, len(resp.Response.ZoneSet))
 	for _, zone := range resp.Response.ZoneSet {
-		if cf.Zone == *zone.Zone {
-			return cvm_client, vpc_client, nil
+		validZones[*zone.Zone] = struct{}{}
+	}
+
+	for _, zone := range cf.CandidateZones() {
+		if _, ok := validZones[zone]; !ok {
+			return nil, nil, fmt.Errorf("unknown zone: %s", zone)
 		}
 	}
 
-	return nil, nil, fmt.Errorf("unknown zone: %s", cf.Zone)
+	return cvm_client, vpc_client, nil
+}
+
+// CandidateZones returns the zones your cvm may be launched in, in the
+// order they should be tried.
+func (cf *TencentCloudAccessConfig) CandidateZones() []string {
+	return uniqueStrings(append([]string{cf.Zone}, cf.Zones...))
 }
 
 func (cf *TencentCloudAccessConfig) Prepare(ctx *interpolate.Context) []error {
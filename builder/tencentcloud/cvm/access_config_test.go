@@ -32,3 +32,21 @@ func TestTencentCloudAccessConfig_Prepare(t *testing.T) {
 		t.Fatalf("shouldn't raise error: %v", err)
 	}
 }
+
+func TestTencentCloudAccessConfig_CandidateZones(t *testing.T) {
+	cf := TencentCloudAccessConfig{
+		Zone:  "ap-guangzhou-3",
+		Zones: []string{"ap-guangzhou-4", "ap-guangzhou-3", "ap-guangzhou-6"},
+	}
+
+	zones := cf.CandidateZones()
+	expected := []string{"ap-guangzhou-3", "ap-guangzhou-4", "ap-guangzhou-6"}
+	if len(zones) != len(expected) {
+		t.Fatalf("invalid candidate zones: %v", zones)
+	}
+	for i := range expected {
+		if zones[i] != expected[i] {
+			t.Fatalf("invalid candidate zones: %v", zones)
+		}
+	}
+}
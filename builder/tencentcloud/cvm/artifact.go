@@ -11,6 +11,7 @@ import (
 	"strings"
 
 	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
+	registryimage "github.com/hashicorp/packer-plugin-sdk/packer/registry/image"
 	cvm "github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/cvm/v20170312"
 )
 
@@ -18,6 +19,9 @@ type Artifact struct {
 	TencentCloudImages map[string]string
 	BuilderIdValue     string
 	Client             *cvm.Client
+	// AccessConfig is used to create the clients of the regions other than
+	// the one of Client, the images are only deleted with Client if unset.
+	AccessConfig *TencentCloudAccessConfig
 
 	// StateData should store data such as GeneratedData
 	// to be shared with post-processors
@@ -60,6 +64,8 @@ func (a *Artifact) State(name string) interface{} {
 	switch name {
 	case "atlas.artifact.metadata":
 		return a.stateAtlasMetadata()
+	case registryimage.ArtifactStateURI:
+		return a.stateHCPPackerRegistryMetadata()
 	default:
 		return nil
 	}
@@ -72,60 +78,79 @@ func (a *Artifact) Destroy() error {
 	for region, imageId := range a.TencentCloudImages {
 		log.Printf("Delete tencentcloud image ID(%s) from region(%s)", imageId, region)
 
-		describeReq := cvm.NewDescribeImagesRequest()
-		describeReq.ImageIds = []*string{&imageId}
-		var describeResp *cvm.DescribeImagesResponse
-		err := Retry(ctx, func(ctx context.Context) error {
-			var e error
-			describeResp, e = a.Client.DescribeImages(describeReq)
-			return e
-		})
+		client, err := a.regionClient(region)
 		if err != nil {
 			errors = append(errors, err)
 			continue
 		}
 
-		if *describeResp.Response.TotalCount == 0 {
-			errors = append(errors, fmt.Errorf(
-				"describe images failed, region(%s) ImageId(%s)", region, imageId))
-		}
+		errors = append(errors, deleteImage(ctx, client, region, imageId)...)
+	}
 
-		var shareAccountIds []*string = nil
-		describeShareReq := cvm.NewDescribeImageSharePermissionRequest()
-		describeShareReq.ImageId = &imageId
-		var describeShareResp *cvm.DescribeImageSharePermissionResponse
-		err = Retry(ctx, func(ctx context.Context) error {
-			var e error
-			describeShareResp, e = a.Client.DescribeImageSharePermission(describeShareReq)
-			return e
-		})
-		if err != nil {
-			errors = append(errors, err)
-		} else {
-			for _, sharePermission := range describeShareResp.Response.SharePermissionSet {
-				shareAccountIds = append(shareAccountIds, sharePermission.AccountId)
-			}
-		}
+	if len(errors) == 1 {
+		return errors[0]
+	} else if len(errors) > 1 {
+		return &packersdk.MultiError{Errors: errors}
+	} else {
+		return nil
+	}
+}
+
+// regionClient returns the cvm client of region.
+func (a *Artifact) regionClient(region string) (*cvm.Client, error) {
+	if a.AccessConfig == nil || a.AccessConfig.Region == region {
+		return a.Client, nil
+	}
+
+	return newRegionCvmClient(a.AccessConfig, region)
+}
+
+// deleteImage cancels the share permissions of the image, then deletes it.
+func deleteImage(ctx context.Context, client *cvm.Client, region string, imageId string) []error {
+	var errors []error
+
+	describeReq := cvm.NewDescribeImagesRequest()
+	describeReq.ImageIds = []*string{&imageId}
+	var describeResp *cvm.DescribeImagesResponse
+	err := Retry(ctx, func(ctx context.Context) error {
+		var e error
+		describeResp, e = client.DescribeImages(describeReq)
+		return e
+	})
+	if err != nil {
+		return append(errors, err)
+	}
 
-		if len(shareAccountIds) != 0 {
-			cancelShareReq := cvm.NewModifyImageSharePermissionRequest()
-			cancelShareReq.ImageId = &imageId
-			cancelShareReq.AccountIds = shareAccountIds
-			CANCEL := "CANCEL"
-			cancelShareReq.Permission = &CANCEL
-			err := Retry(ctx, func(ctx context.Context) error {
-				_, e := a.Client.ModifyImageSharePermission(cancelShareReq)
-				return e
-			})
-			if err != nil {
-				errors = append(errors, err)
-			}
+	if *describeResp.Response.TotalCount == 0 {
+		errors = append(errors, fmt.Errorf(
+			"describe images failed, region(%s) ImageId(%s)", region, imageId))
+	}
+
+	var shareAccountIds []*string = nil
+	describeShareReq := cvm.NewDescribeImageSharePermissionRequest()
+	describeShareReq.ImageId = &imageId
+	var describeShareResp *cvm.DescribeImageSharePermissionResponse
+	err = Retry(ctx, func(ctx context.Context) error {
+		var e error
+		describeShareResp, e = client.DescribeImageSharePermission(describeShareReq)
+		return e
+	})
+	if err != nil {
+		errors = append(errors, err)
+	} else {
+		for _, sharePermission := range describeShareResp.Response.SharePermissionSet {
+			shareAccountIds = append(shareAccountIds, sharePermission.AccountId)
 		}
+	}
 
-		deleteReq := cvm.NewDeleteImagesRequest()
-		deleteReq.ImageIds = []*string{&imageId}
-		err = Retry(ctx, func(ctx context.Context) error {
-			_, e := a.Client.DeleteImages(deleteReq)
+	if len(shareAccountIds) != 0 {
+		cancelShareReq := cvm.NewModifyImageSharePermissionRequest()
+		cancelShareReq.ImageId = &imageId
+		cancelShareReq.AccountIds = shareAccountIds
+		CANCEL := "CANCEL"
+		cancelShareReq.Permission = &CANCEL
+		err := Retry(ctx, func(ctx context.Context) error {
+			_, e := client.ModifyImageSharePermission(cancelShareReq)
 			return e
 		})
 		if err != nil {
@@ -133,13 +158,17 @@ func (a *Artifact) Destroy() error {
 		}
 	}
 
-	if len(errors) == 1 {
-		return errors[0]
-	} else if len(errors) > 1 {
-		return &packersdk.MultiError{Errors: errors}
-	} else {
-		return nil
+	deleteReq := cvm.NewDeleteImagesRequest()
+	deleteReq.ImageIds = []*string{&imageId}
+	err = Retry(ctx, func(ctx context.Context) error {
+		_, e := client.DeleteImages(deleteReq)
+		return e
+	})
+	if err != nil {
+		errors = append(errors, err)
 	}
+
+	return errors
 }
 
 func (a *Artifact) stateAtlasMetadata() interface{} {
@@ -151,3 +180,59 @@ func (a *Artifact) stateAtlasMetadata() interface{} {
 
 	return metadata
 }
+
+// stateHCPPackerRegistryMetadata returns the images of every region for the
+// HCP Packer registry, labelled with what the build used.
+func (a *Artifact) stateHCPPackerRegistryMetadata() interface{} {
+	labels := make(map[string]interface{})
+	for _, key := range []string{"instance_type", "zone"} {
+		if v, ok := a.StateData[key].(string); ok && v != "" {
+			labels[key] = v
+		}
+	}
+
+	var sourceImageId string
+	if image, ok := a.StateData["source_image"].(*cvm.Image); ok && image != nil {
+		sourceImageId = *image.ImageId
+		if image.ImageName != nil {
+			labels["source_image_name"] = *image.ImageName
+		}
+		if image.OsName != nil {
+			labels["os_name"] = *image.OsName
+		}
+		if image.Platform != nil {
+			labels["platform"] = *image.Platform
+		}
+	}
+
+	if tags, ok := a.StateData["image_tags"].(map[string]string); ok {
+		for k, v := range tags {
+			labels["tag."+k] = v
+		}
+	}
+
+	images, err := registryimage.FromMappedData(a.TencentCloudImages, func(k, v interface{}) (*registryimage.Image, error) {
+		region, ok := k.(string)
+		if !ok {
+			return nil, fmt.Errorf("unexpected type for region: %T", k)
+		}
+		imageId, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("unexpected type for image id: %T", v)
+		}
+
+		return registryimage.FromArtifact(a,
+			registryimage.WithProvider("tencentcloud"),
+			registryimage.WithID(imageId),
+			registryimage.WithSourceID(sourceImageId),
+			registryimage.WithRegion(region),
+			registryimage.SetLabels(labels),
+		)
+	})
+	if err != nil {
+		log.Printf("[DEBUG] error encountered when creating HCP Packer registry image metadata: %s", err)
+		return nil
+	}
+
+	return images
+}
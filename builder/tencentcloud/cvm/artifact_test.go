@@ -0,0 +1,109 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package cvm
+
+import (
+	"sort"
+	"strings"
+	"testing"
+
+	registryimage "github.com/hashicorp/packer-plugin-sdk/packer/registry/image"
+	"github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common"
+	cvm "github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/cvm/v20170312"
+)
+
+func TestArtifact_stateHCPPackerRegistryMetadata(t *testing.T) {
+	artifact := &Artifact{
+		TencentCloudImages: map[string]string{
+			"ap-guangzhou": "img-qwer1234",
+			"ap-beijing":   "img-asdf1234",
+		},
+		BuilderIdValue: BuilderId,
+		StateData: map[string]interface{}{
+			"instance_type": "S5.MEDIUM2",
+			"zone":          "ap-guangzhou-3",
+			"source_image": &cvm.Image{
+				ImageId:   common.StringPtr("img-zxcv1234"),
+				ImageName: common.StringPtr("CentOS 7.9 64位"),
+				OsName:    common.StringPtr("CentOS 7.9 64位"),
+				Platform:  common.StringPtr("CentOS"),
+			},
+			"image_tags": map[string]string{"team": "infra"},
+		},
+	}
+
+	images, ok := artifact.State(registryimage.ArtifactStateURI).([]*registryimage.Image)
+	if !ok || len(images) != 2 {
+		t.Fatalf("invalid registry metadata: %#v", artifact.State(registryimage.ArtifactStateURI))
+	}
+	sort.Slice(images, func(i, j int) bool { return images[i].ProviderRegion < images[j].ProviderRegion })
+
+	if images[0].ProviderRegion != "ap-beijing" || images[0].ImageID != "img-asdf1234" ||
+		images[1].ProviderRegion != "ap-guangzhou" || images[1].ImageID != "img-qwer1234" {
+		t.Fatalf("invalid images: %v", images)
+	}
+
+	for _, image := range images {
+		if err := image.Validate(); err != nil {
+			t.Fatalf("invalid image %s: %v", image, err)
+		}
+		if image.ProviderName != "tencentcloud" || image.SourceImageID != "img-zxcv1234" {
+			t.Fatalf("invalid image: %#v", image)
+		}
+		expected := map[string]string{
+			"instance_type":     "S5.MEDIUM2",
+			"zone":              "ap-guangzhou-3",
+			"source_image_name": "CentOS 7.9 64位",
+			"os_name":           "CentOS 7.9 64位",
+			"platform":          "CentOS",
+			"tag.team":          "infra",
+		}
+		for k, v := range expected {
+			if image.Labels[k] != v {
+				t.Fatalf("invalid label %s: %q", k, image.Labels[k])
+			}
+		}
+	}
+}
+
+func TestArtifact_Destroy(t *testing.T) {
+	fake, accessConfig := newFakeCvm(t, map[string]fakeCvmHandler{
+		"DescribeImages": func(call fakeCvmCall) interface{} {
+			// Every region has one shared image.
+			return map[string]interface{}{
+				"ImageSet":   []map[string]string{{"ImageId": "img-" + call.Region}},
+				"TotalCount": 1,
+			}
+		},
+		"DescribeImageSharePermission": func(fakeCvmCall) interface{} {
+			return map[string]interface{}{
+				"SharePermissionSet": []map[string]string{{"AccountId": "100000000001"}},
+			}
+		},
+		"ModifyImageSharePermission": func(fakeCvmCall) interface{} { return nil },
+		"DeleteImages":               func(fakeCvmCall) interface{} { return nil },
+	})
+	client, err := NewCvmClient(accessConfig)
+	if err != nil {
+		t.Fatalf("shouldn't have err: %v", err)
+	}
+
+	artifact := &Artifact{
+		TencentCloudImages: map[string]string{
+			"ap-guangzhou": "img-ap-guangzhou",
+			"ap-shanghai":  "img-ap-shanghai",
+		},
+		Client:       client,
+		AccessConfig: accessConfig,
+	}
+	if err := artifact.Destroy(); err != nil {
+		t.Fatalf("shouldn't have err: %v", err)
+	}
+
+	for _, action := range []string{"ModifyImageSharePermission", "DeleteImages"} {
+		if regions := strings.Join(fake.regions(action), ","); regions != "ap-guangzhou,ap-shanghai" {
+			t.Fatalf("%s should be called in every region: %s", action, regions)
+		}
+	}
+}
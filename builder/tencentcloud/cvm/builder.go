@@ -15,6 +15,7 @@ import (
 	"github.com/hashicorp/packer-plugin-sdk/multistep"
 	"github.com/hashicorp/packer-plugin-sdk/multistep/commonsteps"
 	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
+	"github.com/hashicorp/packer-plugin-sdk/packerbuilderdata"
 	"github.com/hashicorp/packer-plugin-sdk/template/config"
 	"github.com/hashicorp/packer-plugin-sdk/template/interpolate"
 )
@@ -64,6 +65,7 @@ func (b *Builder) Prepare(raws ...interface{}) ([]string, []string, error) {
 	var errs *packersdk.MultiError
 	errs = packersdk.MultiErrorAppend(errs, b.config.TencentCloudAccessConfig.Prepare(&b.config.ctx)...)
 	errs = packersdk.MultiErrorAppend(errs, b.config.TencentCloudImageConfig.Prepare(&b.config.ctx)...)
+	errs = packersdk.MultiErrorAppend(errs, b.config.TencentCloudImageConfig.CheckRegions(b.config.Region)...)
 	errs = packersdk.MultiErrorAppend(errs, b.config.TencentCloudRunConfig.Prepare(&b.config.ctx)...)
 	if errs != nil && len(errs.Errors) > 0 {
 		return nil, nil, errs
@@ -71,7 +73,21 @@ func (b *Builder) Prepare(raws ...interface{}) ([]string, []string, error) {
 
 	packersdk.LogSecretFilter.Set(b.config.SecretId, b.config.SecretKey)
 
-	return nil, nil, nil
+	generatedData := []string{
+		"SourceImageId",
+		"SourceImageName",
+		"SourceImagePlatform",
+		"InstanceId",
+		"InstanceType",
+		"Zone",
+		"VpcId",
+		"SubnetId",
+		"SecurityGroupId",
+		"PrivateIp",
+		"PublicIp",
+	}
+
+	return generatedData, nil, nil
 }
 
 func (b *Builder) Run(ctx context.Context, ui packersdk.Ui, hook packersdk.Hook) (packersdk.Artifact, error) {
@@ -80,50 +96,78 @@ func (b *Builder) Run(ctx context.Context, ui packersdk.Ui, hook packersdk.Hook)
 		return nil, err
 	}
 
+	tatClient, err := NewTatClient(&b.config.TencentCloudAccessConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	cbsClient, err := NewCbsClient(&b.config.TencentCloudAccessConfig)
+	if err != nil {
+		return nil, err
+	}
+
 	state := new(multistep.BasicStateBag)
 	state.Put("config", &b.config)
 	state.Put("cvm_client", cvmClient)
 	state.Put("vpc_client", vpcClient)
+	state.Put("tat_client", tatClient)
+	state.Put("cbs_client", cbsClient)
 	state.Put("hook", hook)
 	state.Put("ui", ui)
+	generatedData := &packerbuilderdata.GeneratedData{State: state}
 
 	// Build the steps
 	var steps []multistep.Step
 	steps = []multistep.Step{
-		&stepPreValidate{},
+		&stepPreValidate{
+			ImageName: b.config.ImageName,
+		},
 		&stepCheckSourceImage{
-			b.config.SourceImageId,
+			GeneratedData: generatedData,
 		},
 		&stepConfigKeyPair{
 			Debug:        b.config.PackerDebug,
 			Comm:         &b.config.Comm,
 			DebugKeyPath: fmt.Sprintf("cvm_%s.pem", b.config.PackerBuildName),
 		},
+		&stepSelectZone{
+			Zones:                  b.config.CandidateZones(),
+			InstanceTypes:          b.config.CandidateInstanceTypes(),
+			InstanceChargeType:     b.config.InstanceChargeType,
+			SpotFallbackToPostpaid: b.config.SpotFallbackToPostpaid,
+		},
 		&stepConfigVPC{
 			VpcId:     b.config.VpcId,
+			VpcFilter: b.config.VpcFilter,
 			CidrBlock: b.config.CidrBlock,
 			VpcName:   b.config.VpcName,
 		},
 		&stepConfigSubnet{
 			SubnetId:        b.config.SubnetId,
+			SubnetFilter:    b.config.SubnetFilter,
 			SubnetCidrBlock: b.config.SubnectCidrBlock,
 			SubnetName:      b.config.SubnetName,
-			Zone:            b.config.Zone,
 		},
 		&stepConfigSecurityGroup{
-			SecurityGroupId:   b.config.SecurityGroupId,
-			SecurityGroupName: b.config.SecurityGroupName,
-			Description:       "securitygroup for packer",
+			SecurityGroupId:     b.config.SecurityGroupId,
+			SecurityGroupFilter: b.config.SecurityGroupFilter,
+			SecurityGroupName:   b.config.SecurityGroupName,
+			Description:         "securitygroup for packer",
+			Port:                b.config.Comm.Port(),
+			SourceCidrs:         b.config.TemporarySecurityGroupSourceCidrs,
+			SourcePublicIp:      b.config.TemporarySecurityGroupSourcePublicIp,
+			EgressCidrs:         b.config.TemporarySecurityGroupEgressCidrs,
+			SecurityGroupIds:    b.config.SecurityGroupIds,
 		},
 		&stepRunInstance{
-			InstanceType:             b.config.InstanceType,
+			InstanceTypes:            b.config.CandidateInstanceTypes(),
 			InstanceChargeType:       b.config.InstanceChargeType,
 			UserData:                 b.config.UserData,
 			UserDataFile:             b.config.UserDataFile,
-			ZoneId:                   b.config.Zone,
 			InstanceName:             b.config.InstanceName,
 			DiskType:                 b.config.DiskType,
 			DiskSize:                 b.config.DiskSize,
+			DiskAutoGrow:             b.config.DiskAutoGrow,
 			DataDisks:                b.config.DataDisks,
 			HostName:                 b.config.HostName,
 			InternetChargeType:       b.config.InternetChargeType,
@@ -132,11 +176,22 @@ func (b *Builder) Run(ctx context.Context, ui packersdk.Ui, hook packersdk.Hook)
 			AssociatePublicIpAddress: b.config.AssociatePublicIpAddress,
 			CamRoleName:              b.config.CamRoleName,
 			Tags:                     b.config.RunTags,
+			SpotPrice:                b.config.SpotPrice,
+			SpotInstanceType:         b.config.SpotInstanceType,
+			SpotFallbackToPostpaid:   b.config.SpotFallbackToPostpaid,
+			GeneratedData:            generatedData,
 		},
 		&communicator.StepConnect{
 			Config:    &b.config.TencentCloudRunConfig.Comm,
 			SSHConfig: b.config.TencentCloudRunConfig.Comm.SSHConfigFunc(),
 			Host:      SSHHost(b.config.AssociatePublicIpAddress),
+			CustomConnect: map[string]multistep.Step{
+				"tat": &stepConnectTat{
+					Username:       b.config.TatUsername,
+					CommandTimeout: b.config.TatCommandTimeout,
+					AgentTimeout:   b.config.TatAgentTimeout,
+				},
+			},
 		},
 		&commonsteps.StepProvision{},
 		&commonsteps.StepCleanupTempKeys{
@@ -146,12 +201,19 @@ func (b *Builder) Run(ctx context.Context, ui packersdk.Ui, hook packersdk.Hook)
 		// it always fails to delete the key.
 		&stepDetachTempKeyPair{},
 		&stepCreateImage{},
-		&stepShareImage{
-			b.config.ImageShareAccounts,
-		},
 		&stepCopyImage{
 			DesinationRegions: b.config.ImageCopyRegions,
 			SourceRegion:      b.config.Region,
+			AccessConfig:      &b.config.TencentCloudAccessConfig,
+			ImageName:         b.config.ImageName,
+			AllowPartial:      b.config.ImageCopyAllowPartial,
+			RegionImages:      b.config.RegionImages(),
+			ImageTags:         b.config.ImageTags,
+		},
+		&stepShareImage{
+			ShareAccounts:       b.config.ImageShareAccounts,
+			RegionShareAccounts: b.config.RegionShareAccounts(),
+			AccessConfig:        &b.config.TencentCloudAccessConfig,
 		},
 	}
 
@@ -170,7 +232,14 @@ func (b *Builder) Run(ctx context.Context, ui packersdk.Ui, hook packersdk.Hook)
 		TencentCloudImages: state.Get("tencentcloudimages").(map[string]string),
 		BuilderIdValue:     BuilderId,
 		Client:             cvmClient,
-		StateData:          map[string]interface{}{"generated_data": state.Get("generated_data")},
+		AccessConfig:       &b.config.TencentCloudAccessConfig,
+		StateData: map[string]interface{}{
+			"generated_data": state.Get("generated_data"),
+			"instance_type":  state.Get("instance_type"),
+			"zone":           state.Get("zone"),
+			"source_image":   state.Get("source_image"),
+			"image_tags":     b.config.ImageTags,
+		},
 	}
 
 	return artifact, nil
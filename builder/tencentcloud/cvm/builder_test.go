@@ -0,0 +1,42 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package cvm
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestBuilder_Prepare_GeneratedData(t *testing.T) {
+	var b Builder
+	generatedData, _, err := b.Prepare(map[string]interface{}{
+		"secret_id":       "secret-id",
+		"secret_key":      "secret-key",
+		"region":          "ap-guangzhou",
+		"instance_type":   "S5.MEDIUM2",
+		"source_image_id": "img-qwer1234",
+		"image_name":      "packer-test",
+		"ssh_username":    "root",
+	})
+	if err != nil {
+		t.Fatalf("shouldn't have err: %v", err)
+	}
+
+	expected := []string{
+		"SourceImageId",
+		"SourceImageName",
+		"SourceImagePlatform",
+		"InstanceId",
+		"InstanceType",
+		"Zone",
+		"VpcId",
+		"SubnetId",
+		"SecurityGroupId",
+		"PrivateIp",
+		"PublicIp",
+	}
+	if !reflect.DeepEqual(generatedData, expected) {
+		t.Fatalf("invalid generated data: %v", generatedData)
+	}
+}
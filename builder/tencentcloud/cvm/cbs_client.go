@@ -0,0 +1,285 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package cvm
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common"
+	tchttp "github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common/http"
+	"github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common/profile"
+)
+
+const cbsAPIVersion = "2017-03-12"
+
+// CbsClient is a client of the Cloud Block Storage api, covering the few
+// actions the builders need, as the sdk has no package for it.
+type CbsClient struct {
+	common.Client
+}
+
+func newCbsClient(credential common.CredentialIface, region string, cpf *profile.ClientProfile) *CbsClient {
+	client := &CbsClient{}
+	client.Init(region).
+		WithCredential(credential).
+		WithProfile(cpf)
+	return client
+}
+
+type cbsBaseRequest struct {
+	*tchttp.BaseRequest
+}
+
+func newCbsBaseRequest(action string) cbsBaseRequest {
+	r := cbsBaseRequest{BaseRequest: &tchttp.BaseRequest{}}
+	r.Init().WithApiInfo("cbs", cbsAPIVersion, action)
+	return r
+}
+
+func (c *CbsClient) send(ctx context.Context, req tchttp.Request, resp tchttp.Response) error {
+	return Retry(ctx, func(ctx context.Context) error {
+		req.SetContext(ctx)
+		return c.Send(req, resp)
+	})
+}
+
+type cbsPlacement struct {
+	Zone *string `json:"Zone,omitempty"`
+}
+
+type CbsDisk struct {
+	DiskId     *string `json:"DiskId,omitempty"`
+	DiskState  *string `json:"DiskState,omitempty"`
+	DiskSize   *uint64 `json:"DiskSize,omitempty"`
+	InstanceId *string `json:"InstanceId,omitempty"`
+	Attached   *bool   `json:"Attached,omitempty"`
+}
+
+type CbsSnapshot struct {
+	SnapshotId    *string `json:"SnapshotId,omitempty"`
+	SnapshotState *string `json:"SnapshotState,omitempty"`
+	Percent       *uint64 `json:"Percent,omitempty"`
+	Encrypt       *bool   `json:"Encrypt,omitempty"`
+}
+
+type cbsCreateDisksRequest struct {
+	cbsBaseRequest
+
+	Placement      *cbsPlacement `json:"Placement,omitempty"`
+	DiskChargeType *string       `json:"DiskChargeType,omitempty"`
+	DiskType       *string       `json:"DiskType,omitempty"`
+	DiskName       *string       `json:"DiskName,omitempty"`
+	DiskSize       *uint64       `json:"DiskSize,omitempty"`
+	SnapshotId     *string       `json:"SnapshotId,omitempty"`
+	DiskCount      *uint64       `json:"DiskCount,omitempty"`
+}
+
+type cbsCreateDisksResponse struct {
+	*tchttp.BaseResponse
+	Response *struct {
+		DiskIdSet []*string `json:"DiskIdSet,omitempty"`
+		RequestId *string   `json:"RequestId,omitempty"`
+	} `json:"Response"`
+}
+
+// CreateDisk creates a pay as you go disk from the snapshot, returning its id
+func (c *CbsClient) CreateDisk(ctx context.Context, zone, diskType, name, snapshotId string, size uint64) (string, error) {
+	req := &cbsCreateDisksRequest{
+		cbsBaseRequest: newCbsBaseRequest("CreateDisks"),
+		Placement:      &cbsPlacement{Zone: &zone},
+		DiskChargeType: common.StringPtr("POSTPAID_BY_HOUR"),
+		DiskType:       &diskType,
+		DiskName:       &name,
+		DiskSize:       &size,
+		SnapshotId:     &snapshotId,
+		DiskCount:      common.Uint64Ptr(1),
+	}
+	resp := &cbsCreateDisksResponse{BaseResponse: &tchttp.BaseResponse{}}
+	if err := c.send(ctx, req, resp); err != nil {
+		return "", err
+	}
+	if len(resp.Response.DiskIdSet) == 0 {
+		return "", fmt.Errorf("no disk id returned")
+	}
+
+	return *resp.Response.DiskIdSet[0], nil
+}
+
+type cbsDescribeDisksRequest struct {
+	cbsBaseRequest
+
+	DiskIds []*string `json:"DiskIds,omitempty"`
+}
+
+type cbsDescribeDisksResponse struct {
+	*tchttp.BaseResponse
+	Response *struct {
+		TotalCount *uint64    `json:"TotalCount,omitempty"`
+		DiskSet    []*CbsDisk `json:"DiskSet,omitempty"`
+		RequestId  *string    `json:"RequestId,omitempty"`
+	} `json:"Response"`
+}
+
+// DescribeDisk gets the disk by id
+func (c *CbsClient) DescribeDisk(ctx context.Context, diskId string) (*CbsDisk, error) {
+	req := &cbsDescribeDisksRequest{
+		cbsBaseRequest: newCbsBaseRequest("DescribeDisks"),
+		DiskIds:        []*string{&diskId},
+	}
+	resp := &cbsDescribeDisksResponse{BaseResponse: &tchttp.BaseResponse{}}
+	if err := c.send(ctx, req, resp); err != nil {
+		return nil, err
+	}
+	if len(resp.Response.DiskSet) == 0 {
+		return nil, fmt.Errorf("disk(%s) not exist", diskId)
+	}
+
+	return resp.Response.DiskSet[0], nil
+}
+
+type cbsAttachDisksRequest struct {
+	cbsBaseRequest
+
+	DiskIds    []*string `json:"DiskIds,omitempty"`
+	InstanceId *string   `json:"InstanceId,omitempty"`
+}
+
+type cbsDetachDisksRequest struct {
+	cbsBaseRequest
+
+	DiskIds    []*string `json:"DiskIds,omitempty"`
+	InstanceId *string   `json:"InstanceId,omitempty"`
+}
+
+type cbsTerminateDisksRequest struct {
+	cbsBaseRequest
+
+	DiskIds []*string `json:"DiskIds,omitempty"`
+}
+
+// AttachDisk attaches the disk to the instance
+func (c *CbsClient) AttachDisk(ctx context.Context, diskId, instanceId string) error {
+	req := &cbsAttachDisksRequest{
+		cbsBaseRequest: newCbsBaseRequest("AttachDisks"),
+		DiskIds:        []*string{&diskId},
+		InstanceId:     &instanceId,
+	}
+	return c.send(ctx, req, &tchttp.BaseResponse{})
+}
+
+// DetachDisk detaches the disk from the instance
+func (c *CbsClient) DetachDisk(ctx context.Context, diskId, instanceId string) error {
+	req := &cbsDetachDisksRequest{
+		cbsBaseRequest: newCbsBaseRequest("DetachDisks"),
+		DiskIds:        []*string{&diskId},
+		InstanceId:     &instanceId,
+	}
+	return c.send(ctx, req, &tchttp.BaseResponse{})
+}
+
+// TerminateDisk terminates the disk
+func (c *CbsClient) TerminateDisk(ctx context.Context, diskId string) error {
+	req := &cbsTerminateDisksRequest{
+		cbsBaseRequest: newCbsBaseRequest("TerminateDisks"),
+		DiskIds:        []*string{&diskId},
+	}
+	return c.send(ctx, req, &tchttp.BaseResponse{})
+}
+
+// WaitForDisk waits for the disk to reach the state
+func (c *CbsClient) WaitForDisk(ctx context.Context, diskId, state string, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		disk, err := c.DescribeDisk(ctx, diskId)
+		if err != nil {
+			return err
+		}
+		if disk.DiskState != nil && *disk.DiskState == state {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("wait disk(%s) state(%s) timeout", diskId, state)
+		}
+		time.Sleep(DefaultWaitForInterval * time.Second)
+	}
+}
+
+type cbsCreateSnapshotRequest struct {
+	cbsBaseRequest
+
+	DiskId       *string `json:"DiskId,omitempty"`
+	SnapshotName *string `json:"SnapshotName,omitempty"`
+}
+
+type cbsCreateSnapshotResponse struct {
+	*tchttp.BaseResponse
+	Response *struct {
+		SnapshotId *string `json:"SnapshotId,omitempty"`
+		RequestId  *string `json:"RequestId,omitempty"`
+	} `json:"Response"`
+}
+
+// CreateSnapshot creates a snapshot of the disk, returning its id
+func (c *CbsClient) CreateSnapshot(ctx context.Context, diskId, name string) (string, error) {
+	req := &cbsCreateSnapshotRequest{
+		cbsBaseRequest: newCbsBaseRequest("CreateSnapshot"),
+		DiskId:         &diskId,
+		SnapshotName:   &name,
+	}
+	resp := &cbsCreateSnapshotResponse{BaseResponse: &tchttp.BaseResponse{}}
+	if err := c.send(ctx, req, resp); err != nil {
+		return "", err
+	}
+
+	return *resp.Response.SnapshotId, nil
+}
+
+type cbsDescribeSnapshotsRequest struct {
+	cbsBaseRequest
+
+	SnapshotIds []*string `json:"SnapshotIds,omitempty"`
+}
+
+type cbsDescribeSnapshotsResponse struct {
+	*tchttp.BaseResponse
+	Response *struct {
+		TotalCount  *uint64        `json:"TotalCount,omitempty"`
+		SnapshotSet []*CbsSnapshot `json:"SnapshotSet,omitempty"`
+		RequestId   *string        `json:"RequestId,omitempty"`
+	} `json:"Response"`
+}
+
+type cbsDeleteSnapshotsRequest struct {
+	cbsBaseRequest
+
+	SnapshotIds []*string `json:"SnapshotIds,omitempty"`
+}
+
+// DescribeSnapshot gets the snapshot by id
+func (c *CbsClient) DescribeSnapshot(ctx context.Context, snapshotId string) (*CbsSnapshot, error) {
+	req := &cbsDescribeSnapshotsRequest{
+		cbsBaseRequest: newCbsBaseRequest("DescribeSnapshots"),
+		SnapshotIds:    []*string{&snapshotId},
+	}
+	resp := &cbsDescribeSnapshotsResponse{BaseResponse: &tchttp.BaseResponse{}}
+	if err := c.send(ctx, req, resp); err != nil {
+		return nil, err
+	}
+	if len(resp.Response.SnapshotSet) == 0 {
+		return nil, fmt.Errorf("snapshot(%s) not exist", snapshotId)
+	}
+
+	return resp.Response.SnapshotSet[0], nil
+}
+
+// DeleteSnapshot deletes the snapshot
+func (c *CbsClient) DeleteSnapshot(ctx context.Context, snapshotId string) error {
+	req := &cbsDeleteSnapshotsRequest{
+		cbsBaseRequest: newCbsBaseRequest("DeleteSnapshots"),
+		SnapshotIds:    []*string{&snapshotId},
+	}
+	return c.send(ctx, req, &tchttp.BaseResponse{})
+}
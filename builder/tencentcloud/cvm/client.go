@@ -20,6 +20,9 @@ type TencentCloudClient struct {
 	vpcConn *vpc.Client
 	cvmConn *cvm.Client
 	stsConn *sts.Client
+	tatConn *TatClient
+	cbsConn *CbsClient
+	tagConn *TagClient
 }
 
 func (me *TencentCloudClient) UseVpcClient(cpf *profile.ClientProfile) *vpc.Client {
@@ -43,6 +46,36 @@ func (me *TencentCloudClient) UseCvmClient(cpf *profile.ClientProfile) *cvm.Clie
 	return me.cvmConn
 }
 
+func (me *TencentCloudClient) UseTatClient(cpf *profile.ClientProfile) *TatClient {
+	if me.tatConn != nil {
+		return me.tatConn
+	}
+
+	me.tatConn = newTatClient(me.Credential, me.Region, cpf)
+
+	return me.tatConn
+}
+
+func (me *TencentCloudClient) UseCbsClient(cpf *profile.ClientProfile) *CbsClient {
+	if me.cbsConn != nil {
+		return me.cbsConn
+	}
+
+	me.cbsConn = newCbsClient(me.Credential, me.Region, cpf)
+
+	return me.cbsConn
+}
+
+func (me *TencentCloudClient) UseTagClient(cpf *profile.ClientProfile) *TagClient {
+	if me.tagConn != nil {
+		return me.tagConn
+	}
+
+	me.tagConn = newTagClient(me.Credential, me.Region, cpf)
+
+	return me.tagConn
+}
+
 func (me *TencentCloudClient) UseStsClient() *sts.Client {
 	if me.stsConn != nil {
 		return me.stsConn
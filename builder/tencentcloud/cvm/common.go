@@ -5,9 +5,14 @@ package cvm
 
 import (
 	"context"
+	stderrors "errors"
 	"fmt"
+	"io"
+	"net"
+	"net/http"
 	"net/url"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
@@ -20,11 +25,20 @@ import (
 	cvm "github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/cvm/v20170312"
 	sts "github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/sts/v20180813"
 	vpc "github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/vpc/v20170312"
+	"github.com/tencentyun/cos-go-sdk-v5"
 )
 
 // DefaultWaitForInterval is sleep interval when wait statue
 const DefaultWaitForInterval = 5
 
+// DefaultDescribeLimit is page size when describe resources
+const DefaultDescribeLimit = 100
+
+// publicIpURL is the service answering the public ip of its caller
+var publicIpURL = "https://api.ipify.org"
+
+var errInstanceLaunchFailed = fmt.Errorf("instance launch failed")
+
 // WaitForInstance wait for instance reaches statue
 func WaitForInstance(ctx context.Context, client *cvm.Client, instanceId string, status string, timeout int) error {
 	req := cvm.NewDescribeInstancesRequest()
@@ -43,6 +57,9 @@ func WaitForInstance(ctx context.Context, client *cvm.Client, instanceId string,
 		if *resp.Response.TotalCount == 0 {
 			return fmt.Errorf("instance(%s) not exist", instanceId)
 		}
+		if *resp.Response.InstanceSet[0].InstanceState == "LAUNCH_FAILED" && status != "LAUNCH_FAILED" {
+			return fmt.Errorf("instance(%s): %w", instanceId, errInstanceLaunchFailed)
+		}
 		if *resp.Response.InstanceSet[0].InstanceState == status &&
 			(resp.Response.InstanceSet[0].LatestOperationState == nil ||
 				*resp.Response.InstanceSet[0].LatestOperationState != "OPERATING") {
@@ -69,8 +86,15 @@ func WaitForImageReady(ctx context.Context, client *cvm.Client, imageName string
 		if image != nil && *image.ImageState == status {
 			return nil
 		}
+		if image != nil && strings.HasSuffix(*image.ImageState, "FAILED") {
+			return fmt.Errorf("image(%s) status is %s", imageName, *image.ImageState)
+		}
 
-		time.Sleep(DefaultWaitForInterval * time.Second)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(DefaultWaitForInterval * time.Second):
+		}
 		timeout = timeout - DefaultWaitForInterval
 		if timeout <= 0 {
 			return fmt.Errorf("wait image(%s) status(%s) timeout", imageName, status)
@@ -109,6 +133,206 @@ func GetImageByName(ctx context.Context, client *cvm.Client, imageName string) (
 	return nil, nil
 }
 
+// GetImages get all images matching the request, reading every page
+func GetImages(ctx context.Context, client *cvm.Client, req *cvm.DescribeImagesRequest) ([]*cvm.Image, error) {
+	var images []*cvm.Image
+
+	req.Limit = common.Uint64Ptr(DefaultDescribeLimit)
+	req.Offset = common.Uint64Ptr(0)
+	for {
+		var resp *cvm.DescribeImagesResponse
+		err := Retry(ctx, func(ctx context.Context) error {
+			var e error
+			resp, e = client.DescribeImages(req)
+			return e
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		images = append(images, resp.Response.ImageSet...)
+		if len(resp.Response.ImageSet) < DefaultDescribeLimit ||
+			int64(len(images)) >= *resp.Response.TotalCount {
+			break
+		}
+		req.Offset = common.Uint64Ptr(uint64(len(images)))
+	}
+
+	return images, nil
+}
+
+// ImageFilters builds the DescribeImages filters on image type, platform
+// and tags, leaving out the ones not set
+func ImageFilters(imageType, platform string, tags map[string]string) []*cvm.Filter {
+	var filters []*cvm.Filter
+	addFilter := func(name, value string) {
+		filters = append(filters, &cvm.Filter{
+			Name:   common.StringPtr(name),
+			Values: []*string{common.StringPtr(value)},
+		})
+	}
+
+	if imageType != "" {
+		addFilter("image-type", imageType)
+	}
+	if platform != "" {
+		addFilter("platform", platform)
+	}
+	for k, v := range tags {
+		addFilter(fmt.Sprintf("tag:%s", k), v)
+	}
+
+	return filters
+}
+
+// GetSubnets get all subnets matching the request, reading every page
+func GetSubnets(ctx context.Context, client *vpc.Client, req *vpc.DescribeSubnetsRequest) ([]*vpc.Subnet, error) {
+	var subnets []*vpc.Subnet
+
+	req.Limit = common.StringPtr(strconv.Itoa(DefaultDescribeLimit))
+	req.Offset = common.StringPtr("0")
+	for {
+		var resp *vpc.DescribeSubnetsResponse
+		err := Retry(ctx, func(ctx context.Context) error {
+			var e error
+			resp, e = client.DescribeSubnets(req)
+			return e
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		subnets = append(subnets, resp.Response.SubnetSet...)
+		if len(resp.Response.SubnetSet) < DefaultDescribeLimit ||
+			uint64(len(subnets)) >= *resp.Response.TotalCount {
+			break
+		}
+		req.Offset = common.StringPtr(strconv.Itoa(len(subnets)))
+	}
+
+	return subnets, nil
+}
+
+// GetVpcs returns all vpcs matching the request, reading every page
+func GetVpcs(ctx context.Context, client *vpc.Client, req *vpc.DescribeVpcsRequest) ([]*vpc.Vpc, error) {
+	var vpcs []*vpc.Vpc
+
+	req.Limit = common.StringPtr(strconv.Itoa(DefaultDescribeLimit))
+	req.Offset = common.StringPtr("0")
+	for {
+		var resp *vpc.DescribeVpcsResponse
+		err := Retry(ctx, func(ctx context.Context) error {
+			var e error
+			resp, e = client.DescribeVpcs(req)
+			return e
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		vpcs = append(vpcs, resp.Response.VpcSet...)
+		if len(resp.Response.VpcSet) < DefaultDescribeLimit ||
+			uint64(len(vpcs)) >= *resp.Response.TotalCount {
+			break
+		}
+		req.Offset = common.StringPtr(strconv.Itoa(len(vpcs)))
+	}
+
+	return vpcs, nil
+}
+
+// GetSecurityGroups returns all security groups matching the request,
+// reading every page
+func GetSecurityGroups(ctx context.Context, client *vpc.Client,
+	req *vpc.DescribeSecurityGroupsRequest) ([]*vpc.SecurityGroup, error) {
+	var groups []*vpc.SecurityGroup
+
+	req.Limit = common.StringPtr(strconv.Itoa(DefaultDescribeLimit))
+	req.Offset = common.StringPtr("0")
+	for {
+		var resp *vpc.DescribeSecurityGroupsResponse
+		err := Retry(ctx, func(ctx context.Context) error {
+			var e error
+			resp, e = client.DescribeSecurityGroups(req)
+			return e
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		groups = append(groups, resp.Response.SecurityGroupSet...)
+		if len(resp.Response.SecurityGroupSet) < DefaultDescribeLimit ||
+			uint64(len(groups)) >= *resp.Response.TotalCount {
+			break
+		}
+		req.Offset = common.StringPtr(strconv.Itoa(len(groups)))
+	}
+
+	return groups, nil
+}
+
+// GetPublicIp returns the public ipv4 address the machine running Packer
+// reaches the internet with
+func GetPublicIp(ctx context.Context) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, publicIpURL, nil)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected status %s from %s", resp.Status, publicIpURL)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 64))
+	if err != nil {
+		return "", err
+	}
+
+	ip := net.ParseIP(strings.TrimSpace(string(body)))
+	if ip == nil || ip.To4() == nil {
+		return "", fmt.Errorf("invalid public ip %q from %s", strings.TrimSpace(string(body)), publicIpURL)
+	}
+
+	return ip.String(), nil
+}
+
+// MostRecentImage returns the image with the latest creation time, the one
+// with the greatest id among images created at the same time
+func MostRecentImage(images []*cvm.Image) *cvm.Image {
+	var latest *cvm.Image
+	var latestTime time.Time
+
+	for _, image := range images {
+		createdTime := imageCreatedTime(image)
+		if latest == nil || createdTime.After(latestTime) ||
+			(createdTime.Equal(latestTime) && *image.ImageId > *latest.ImageId) {
+			latest = image
+			latestTime = createdTime
+		}
+	}
+
+	return latest
+}
+
+func imageCreatedTime(image *cvm.Image) time.Time {
+	if image.CreatedTime == nil {
+		return time.Time{}
+	}
+
+	createdTime, err := time.Parse(time.RFC3339, *image.CreatedTime)
+	if err != nil {
+		return time.Time{}
+	}
+
+	return createdTime
+}
+
 // NewCvmClient returns a new cvm client
 func NewCvmClient(cf *TencentCloudAccessConfig) (client *cvm.Client, err error) {
 	apiV3Conn, err := packerConfigClient(cf)
@@ -126,6 +350,109 @@ func NewCvmClient(cf *TencentCloudAccessConfig) (client *cvm.Client, err error)
 	return
 }
 
+// newRegionCvmClient returns a new cvm client of region, with the same
+// credentials and endpoint as cf.
+func newRegionCvmClient(cf *TencentCloudAccessConfig, region string) (*cvm.Client, error) {
+	regionConfig := *cf
+	regionConfig.Region = region
+
+	return NewCvmClient(&regionConfig)
+}
+
+// NewTatClient returns a new tat client
+func NewTatClient(cf *TencentCloudAccessConfig) (client *TatClient, err error) {
+	apiV3Conn, err := packerConfigClient(cf)
+	if err != nil {
+		return nil, err
+	}
+
+	tatClientProfile, err := newClientProfile(cf.TatEndpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	client = apiV3Conn.UseTatClient(tatClientProfile)
+
+	return
+}
+
+// NewCbsClient returns a new cbs client
+func NewCbsClient(cf *TencentCloudAccessConfig) (client *CbsClient, err error) {
+	apiV3Conn, err := packerConfigClient(cf)
+	if err != nil {
+		return nil, err
+	}
+
+	cbsClientProfile, err := newClientProfile(cf.CbsEndpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	client = apiV3Conn.UseCbsClient(cbsClientProfile)
+
+	return
+}
+
+// NewTagClient returns a new tag client
+func NewTagClient(cf *TencentCloudAccessConfig) (client *TagClient, err error) {
+	apiV3Conn, err := packerConfigClient(cf)
+	if err != nil {
+		return nil, err
+	}
+
+	tagClientProfile, err := newClientProfile(cf.TagEndpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	client = apiV3Conn.UseTagClient(tagClientProfile)
+
+	return
+}
+
+// NewStsClient returns a new sts client
+func NewStsClient(cf *TencentCloudAccessConfig) (client *sts.Client, err error) {
+	apiV3Conn, err := packerConfigClient(cf)
+	if err != nil {
+		return nil, err
+	}
+
+	stsClientProfile, err := newClientProfile(cf.StsEndpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	return sts.NewClient(apiV3Conn.Credential, apiV3Conn.Region, stsClientProfile)
+}
+
+// NewCosClient returns a new client of the cos bucket, reaching the given
+// bucket url if set
+func NewCosClient(cf *TencentCloudAccessConfig, bucket string, endpoint string) (client *cos.Client, err error) {
+	apiV3Conn, err := packerConfigClient(cf)
+	if err != nil {
+		return nil, err
+	}
+
+	bucketURL := fmt.Sprintf("https://%s.cos.%s.myqcloud.com", bucket, cf.Region)
+	if endpoint != "" {
+		bucketURL = endpoint
+	}
+	u, err := url.Parse(bucketURL)
+	if err != nil {
+		return nil, err
+	}
+
+	client = cos.NewClient(&cos.BaseURL{BucketURL: u}, &http.Client{
+		Transport: &cos.AuthorizationTransport{
+			SecretID:     apiV3Conn.Credential.SecretId,
+			SecretKey:    apiV3Conn.Credential.SecretKey,
+			SessionToken: apiV3Conn.Credential.Token,
+		},
+	})
+
+	return
+}
+
 // NewVpcClient returns a new vpc client
 func NewVpcClient(cf *TencentCloudAccessConfig) (client *vpc.Client, err error) {
 	apiV3Conn, err := packerConfigClient(cf)
@@ -187,6 +514,39 @@ func Retry(ctx context.Context, fn func(context.Context) error) error {
 	}.Run(ctx, fn)
 }
 
+// isSoldOutError tell whether the request failed because the resources
+// are sold out
+func isSoldOutError(err error) bool {
+	e, ok := err.(*errors.TencentCloudSDKError)
+	if !ok {
+		return false
+	}
+
+	return strings.HasPrefix(e.Code, "ResourcesSoldOut") ||
+		e.Code == "ResourceInsufficient.AvailabilityZoneSoldOut" ||
+		e.Code == "ResourceInsufficient.SpecifiedInstanceType" ||
+		e.Code == "ResourceInsufficient.ZoneSoldOutForSpecifiedInstance" ||
+		e.Code == "InvalidParameterValue.InsufficientOffering"
+}
+
+// isSpotUnavailableError tell whether the spot instance can't be launched
+// because of the spot market
+func isSpotUnavailableError(err error) bool {
+	if isSoldOutError(err) || stderrors.Is(err, errInstanceLaunchFailed) {
+		return true
+	}
+
+	e, ok := err.(*errors.TencentCloudSDKError)
+	if !ok {
+		return false
+	}
+
+	return e.Code == "InvalidParameterValue.InsufficientPrice" ||
+		e.Code == "LimitExceeded.SpotQuota" || e.Code == "LimitExceeded.UserSpotQuota" ||
+		e.Code == "UnsupportedOperation.NoInstanceTypeSupportSpot" ||
+		e.Code == "UnsupportedOperation.SpotUnsupportedRegion"
+}
+
 // SayClean tell you clean module message
 func SayClean(state multistep.StateBag, module string) {
 	_, halted := state.GetOk(multistep.StateHalted)
@@ -318,7 +678,31 @@ func genClientWithSTS(apiV3Conn *TencentCloudClient, assumeRoleArn, assumeRoleSe
 	return nil
 }
 
+// uniqueStrings removes empty and duplicated values, keeping the order
+func uniqueStrings(values []string) []string {
+	seen := make(map[string]struct{}, len(values))
+	result := make([]string, 0, len(values))
+	for _, v := range values {
+		if _, ok := seen[v]; ok || v == "" {
+			continue
+		}
+		seen[v] = struct{}{}
+		result = append(result, v)
+	}
+
+	return result
+}
+
 func IntUint64(i int) *uint64 {
 	u := uint64(i)
 	return &u
 }
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
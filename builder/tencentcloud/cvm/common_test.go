@@ -0,0 +1,36 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package cvm
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGetPublicIp(t *testing.T) {
+	body := "203.0.113.10\n"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, body)
+	}))
+	defer server.Close()
+
+	defer func(url string) { publicIpURL = url }(publicIpURL)
+	publicIpURL = server.URL
+
+	ip, err := GetPublicIp(context.Background())
+	if err != nil {
+		t.Fatalf("shouldn't have error: %v", err)
+	}
+	if ip != "203.0.113.10" {
+		t.Fatalf("invalid public ip: %v", ip)
+	}
+
+	body = "<html></html>"
+	if _, err := GetPublicIp(context.Background()); err == nil {
+		t.Fatal("should have error")
+	}
+}
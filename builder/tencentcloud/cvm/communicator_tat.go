@@ -0,0 +1,329 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package cvm
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
+	"github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common"
+)
+
+const (
+	tatCommandTypeShell      = "SHELL"
+	tatCommandTypePowerShell = "POWERSHELL"
+
+	// Files are sent and received in chunks, small enough for the command
+	// content limit (64KB) and the output limit (24KB) of tat once base64
+	// encoded.
+	tatUploadChunkSize   = 24 * 1024
+	tatDownloadChunkSize = 12 * 1024
+)
+
+// tatCommunicator is a packersdk.Communicator running commands through the
+// tat agent of the instance, so that no inbound port needs to be opened.
+type tatCommunicator struct {
+	client       *TatClient
+	instanceId   string
+	commandType  string
+	username     string
+	timeout      time.Duration
+	pollInterval time.Duration
+}
+
+var _ packersdk.Communicator = new(tatCommunicator)
+
+func (c *tatCommunicator) Start(ctx context.Context, cmd *packersdk.RemoteCmd) error {
+	log.Printf("[DEBUG] Running tat command: %s", cmd.Command)
+	invocationId, err := c.runCommand(ctx, cmd.Command)
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		exitCode, output, err := c.waitInvocation(ctx, invocationId)
+		if len(output) > 0 && cmd.Stdout != nil {
+			_, _ = cmd.Stdout.Write(output)
+		}
+		if err != nil {
+			log.Printf("[ERROR] tat invocation(%s) failed: %s", invocationId, err)
+			if cmd.Stderr != nil {
+				_, _ = fmt.Fprintln(cmd.Stderr, err)
+			}
+			cmd.SetExited(packersdk.CmdDisconnect)
+			return
+		}
+		cmd.SetExited(exitCode)
+	}()
+
+	return nil
+}
+
+func (c *tatCommunicator) Upload(dst string, r io.Reader, fi *os.FileInfo) error {
+	ctx := context.TODO()
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	// An empty file still needs one command to be created.
+	for offset := 0; offset == 0 || offset < len(data); offset += tatUploadChunkSize {
+		end := offset + tatUploadChunkSize
+		if end > len(data) {
+			end = len(data)
+		}
+		chunk := base64.StdEncoding.EncodeToString(data[offset:end])
+		if err := c.execute(ctx, c.writeChunkCommand(dst, chunk, offset > 0)); err != nil {
+			return fmt.Errorf("failed to upload %s: %s", dst, err)
+		}
+	}
+
+	return nil
+}
+
+func (c *tatCommunicator) UploadDir(dst string, src string, exclude []string) error {
+	// Like the other communicators, without a trailing slash the directory
+	// itself is uploaded, not only its content.
+	if !strings.HasSuffix(src, "/") && !strings.HasSuffix(src, string(filepath.Separator)) {
+		dst = path.Join(dst, filepath.Base(src))
+	}
+
+	return filepath.Walk(src, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, p)
+		if err != nil {
+			return err
+		}
+		target := path.Join(dst, filepath.ToSlash(rel))
+		if info.IsDir() {
+			return c.execute(context.TODO(), c.mkdirCommand(target))
+		}
+
+		f, err := os.Open(p)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		return c.Upload(target, f, &info)
+	})
+}
+
+func (c *tatCommunicator) Download(src string, w io.Writer) error {
+	ctx := context.TODO()
+
+	for offset := 0; ; offset += tatDownloadChunkSize {
+		output, err := c.executeOutput(ctx, c.readChunkCommand(src, offset))
+		if err != nil {
+			return fmt.Errorf("failed to download %s: %s", src, err)
+		}
+		chunk, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(output)))
+		if err != nil {
+			return fmt.Errorf("failed to download %s: %s", src, err)
+		}
+		if _, err := w.Write(chunk); err != nil {
+			return err
+		}
+		if len(chunk) < tatDownloadChunkSize {
+			return nil
+		}
+	}
+}
+
+func (c *tatCommunicator) DownloadDir(src string, dst string, exclude []string) error {
+	return fmt.Errorf("DownloadDir is not supported by the tat communicator")
+}
+
+// execute runs the command and waits for it to succeed.
+func (c *tatCommunicator) execute(ctx context.Context, command string) error {
+	_, err := c.executeOutput(ctx, command)
+	return err
+}
+
+// executeOutput runs the command, waits for it to succeed and returns its
+// output.
+func (c *tatCommunicator) executeOutput(ctx context.Context, command string) ([]byte, error) {
+	invocationId, err := c.runCommand(ctx, command)
+	if err != nil {
+		return nil, err
+	}
+
+	exitCode, output, err := c.waitInvocation(ctx, invocationId)
+	if err != nil {
+		return nil, err
+	}
+	if exitCode != 0 {
+		return nil, fmt.Errorf("command exited with code %d: %s", exitCode, bytes.TrimSpace(output))
+	}
+
+	return output, nil
+}
+
+func (c *tatCommunicator) runCommand(ctx context.Context, command string) (string, error) {
+	req := newTatRunCommandRequest()
+	req.Content = common.StringPtr(base64.StdEncoding.EncodeToString([]byte(command)))
+	req.InstanceIds = []*string{&c.instanceId}
+	req.CommandType = &c.commandType
+	req.Timeout = common.Uint64Ptr(uint64(c.timeout.Seconds()))
+	req.SaveCommand = common.BoolPtr(false)
+	if c.username != "" {
+		req.Username = &c.username
+	}
+
+	var resp *tatRunCommandResponse
+	err := Retry(ctx, func(ctx context.Context) error {
+		var e error
+		resp, e = c.client.runCommand(ctx, req)
+		return e
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return *resp.Response.InvocationId, nil
+}
+
+// waitInvocation waits for the task of the invocation on the instance to
+// finish, returning its exit code and output.
+func (c *tatCommunicator) waitInvocation(ctx context.Context, invocationId string) (int, []byte, error) {
+	req := newTatDescribeInvocationTasksRequest()
+	req.Filters = []*tatFilter{
+		{
+			Name:   common.StringPtr("invocation-id"),
+			Values: []*string{&invocationId},
+		},
+		{
+			Name:   common.StringPtr("instance-id"),
+			Values: []*string{&c.instanceId},
+		},
+	}
+
+	for {
+		var resp *tatDescribeInvocationTasksResponse
+		err := Retry(ctx, func(ctx context.Context) error {
+			var e error
+			resp, e = c.client.describeInvocationTasks(ctx, req)
+			return e
+		})
+		if err != nil {
+			return 0, nil, err
+		}
+
+		if len(resp.Response.InvocationTaskSet) > 0 {
+			task := resp.Response.InvocationTaskSet[0]
+			switch status := *task.TaskStatus; status {
+			case "SUCCESS", "FAILED":
+				return tatTaskOutput(task)
+			case "PENDING", "DELIVERING", "DELIVER_DELAYED", "RUNNING", "CANCELLING":
+			default:
+				var errorInfo string
+				if task.ErrorInfo != nil {
+					errorInfo = *task.ErrorInfo
+				}
+				_, output, _ := tatTaskOutput(task)
+				return 0, output, fmt.Errorf("tat task(%s) ended with status %s: %s",
+					*task.InvocationTaskId, status, errorInfo)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return 0, nil, ctx.Err()
+		case <-time.After(c.pollInterval):
+		}
+	}
+}
+
+func tatTaskOutput(task *tatInvocationTask) (int, []byte, error) {
+	if task.TaskResult == nil {
+		return 0, nil, fmt.Errorf("tat task(%s) has no result", *task.InvocationTaskId)
+	}
+
+	var output []byte
+	if task.TaskResult.Output != nil {
+		var err error
+		output, err = base64.StdEncoding.DecodeString(*task.TaskResult.Output)
+		if err != nil {
+			return 0, nil, err
+		}
+	}
+	if task.TaskResult.Dropped != nil && *task.TaskResult.Dropped > 0 {
+		log.Printf("[WARN] %d bytes of output of tat task(%s) are dropped",
+			*task.TaskResult.Dropped, *task.InvocationTaskId)
+	}
+
+	var exitCode int
+	if task.TaskResult.ExitCode != nil {
+		exitCode = int(*task.TaskResult.ExitCode)
+	}
+
+	return exitCode, output, nil
+}
+
+func (c *tatCommunicator) writeChunkCommand(dst string, chunk string, appendChunk bool) string {
+	if c.commandType == tatCommandTypePowerShell {
+		mode := "Create"
+		if appendChunk {
+			mode = "Append"
+		}
+		return fmt.Sprintf("$ErrorActionPreference = 'Stop'\n"+
+			"$dir = Split-Path -Parent %[1]s\n"+
+			"if ($dir) { New-Item -ItemType Directory -Force -Path $dir | Out-Null }\n"+
+			"$bytes = [Convert]::FromBase64String('%[2]s')\n"+
+			"$f = New-Object IO.FileStream(%[1]s, [IO.FileMode]::%[3]s)\n"+
+			"$f.Write($bytes, 0, $bytes.Length)\n"+
+			"$f.Close()", powerShellQuote(dst), chunk, mode)
+	}
+
+	redirect := ">"
+	if appendChunk {
+		redirect = ">>"
+	}
+	return fmt.Sprintf("set -e\nmkdir -p \"$(dirname %[1]s)\"\nprintf '%%s' '%[2]s' | base64 -d %[3]s %[1]s",
+		shellQuote(dst), chunk, redirect)
+}
+
+func (c *tatCommunicator) readChunkCommand(src string, offset int) string {
+	if c.commandType == tatCommandTypePowerShell {
+		return fmt.Sprintf("$ErrorActionPreference = 'Stop'\n"+
+			"$f = [IO.File]::OpenRead(%[1]s)\n"+
+			"$f.Seek(%[2]d, [IO.SeekOrigin]::Begin) | Out-Null\n"+
+			"$bytes = New-Object byte[] %[3]d\n"+
+			"$n = $f.Read($bytes, 0, %[3]d)\n"+
+			"$f.Close()\n"+
+			"[Convert]::ToBase64String($bytes, 0, $n)", powerShellQuote(src), offset, tatDownloadChunkSize)
+	}
+
+	return fmt.Sprintf("set -e\ntest -f %[1]s\ndd if=%[1]s bs=%[2]d skip=%[3]d count=1 2>/dev/null | base64 | tr -d '\\n'",
+		shellQuote(src), tatDownloadChunkSize, offset/tatDownloadChunkSize)
+}
+
+func (c *tatCommunicator) mkdirCommand(dir string) string {
+	if c.commandType == tatCommandTypePowerShell {
+		return fmt.Sprintf("New-Item -ItemType Directory -Force -Path %s | Out-Null", powerShellQuote(dir))
+	}
+
+	return fmt.Sprintf("mkdir -p %s", shellQuote(dir))
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+func powerShellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
@@ -0,0 +1,203 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package cvm
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
+	"github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common"
+)
+
+// fakeTatServer implements the tat actions used by the communicator,
+// running the shell commands on the local machine.
+type fakeTatServer struct {
+	mu    sync.Mutex
+	tasks map[string]*tatInvocationTask
+}
+
+func (f *fakeTatServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var response interface{}
+
+	switch action := r.Header.Get("X-TC-Action"); action {
+	case "DescribeAutomationAgentStatus":
+		response = map[string]interface{}{
+			"AutomationAgentSet": []map[string]string{
+				{"InstanceId": "ins-qwer1234", "AgentStatus": "Online", "Environment": "Linux"},
+			},
+			"TotalCount": 1,
+		}
+	case "RunCommand":
+		var req struct {
+			Content     string
+			CommandType string
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		command, _ := base64.StdEncoding.DecodeString(req.Content)
+		output, err := exec.Command("/bin/sh", "-c", string(command)).CombinedOutput()
+		exitCode := int64(0)
+		status := "SUCCESS"
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			exitCode = int64(exitErr.ExitCode())
+			status = "FAILED"
+		}
+
+		f.mu.Lock()
+		id := fmt.Sprintf("inv-%08d", len(f.tasks))
+		f.tasks[id] = &tatInvocationTask{
+			InvocationId:     common.StringPtr(id),
+			InvocationTaskId: common.StringPtr("invt-" + id),
+			InstanceId:       common.StringPtr("ins-qwer1234"),
+			TaskStatus:       common.StringPtr(status),
+			TaskResult: &tatTaskResult{
+				ExitCode: &exitCode,
+				Output:   common.StringPtr(base64.StdEncoding.EncodeToString(output)),
+			},
+		}
+		f.mu.Unlock()
+
+		response = map[string]string{"CommandId": "cmd-qwer1234", "InvocationId": id}
+	case "DescribeInvocationTasks":
+		var req tatDescribeInvocationTasksRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		f.mu.Lock()
+		task := f.tasks[*req.Filters[0].Values[0]]
+		f.mu.Unlock()
+		response = map[string]interface{}{
+			"InvocationTaskSet": []*tatInvocationTask{task},
+			"TotalCount":        1,
+		}
+	default:
+		http.Error(w, "unknown action "+action, http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"Response": response})
+}
+
+func testTatCommunicator(t *testing.T) *tatCommunicator {
+	server := httptest.NewServer(&fakeTatServer{tasks: make(map[string]*tatInvocationTask)})
+	t.Cleanup(server.Close)
+
+	cpf, err := newClientProfile(server.URL)
+	if err != nil {
+		t.Fatalf("shouldn't have error: %v", err)
+	}
+
+	return &tatCommunicator{
+		client:       newTatClient(common.NewCredential("id", "key"), "ap-guangzhou", cpf),
+		instanceId:   "ins-qwer1234",
+		commandType:  tatCommandTypeShell,
+		timeout:      time.Minute,
+		pollInterval: time.Millisecond,
+	}
+}
+
+func TestTatCommunicator_Start(t *testing.T) {
+	comm := testTatCommunicator(t)
+
+	var stdout bytes.Buffer
+	cmd := &packersdk.RemoteCmd{
+		Command: "echo hello; exit 3",
+		Stdout:  &stdout,
+	}
+	if err := comm.Start(context.Background(), cmd); err != nil {
+		t.Fatalf("shouldn't have error: %v", err)
+	}
+
+	if exitCode := cmd.Wait(); exitCode != 3 {
+		t.Fatalf("invalid exit code: %d", exitCode)
+	}
+	if stdout.String() != "hello\n" {
+		t.Fatalf("invalid output: %q", stdout.String())
+	}
+}
+
+func TestTatCommunicator_UploadDownload(t *testing.T) {
+	comm := testTatCommunicator(t)
+
+	// Several chunks in both directions.
+	data := bytes.Repeat([]byte("packer\x00tat\n"), 5000)
+	dst := filepath.Join(t.TempDir(), "dir", "file's")
+
+	if err := comm.Upload(dst, bytes.NewReader(data), nil); err != nil {
+		t.Fatalf("shouldn't have error: %v", err)
+	}
+	uploaded, err := os.ReadFile(dst)
+	if err != nil {
+		t.Fatalf("shouldn't have error: %v", err)
+	}
+	if !bytes.Equal(uploaded, data) {
+		t.Fatalf("uploaded file has %d bytes, expected %d", len(uploaded), len(data))
+	}
+
+	var downloaded bytes.Buffer
+	if err := comm.Download(dst, &downloaded); err != nil {
+		t.Fatalf("shouldn't have error: %v", err)
+	}
+	if !bytes.Equal(downloaded.Bytes(), data) {
+		t.Fatalf("downloaded file has %d bytes, expected %d", downloaded.Len(), len(data))
+	}
+
+	if err := comm.Download(dst+".missing", &downloaded); err == nil {
+		t.Fatal("should have error")
+	}
+}
+
+func TestTatCommunicator_UploadDir(t *testing.T) {
+	comm := testTatCommunicator(t)
+
+	src := filepath.Join(t.TempDir(), "src")
+	if err := os.MkdirAll(filepath.Join(src, "sub", "empty"), 0755); err != nil {
+		t.Fatalf("shouldn't have error: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(src, "sub", "file"), []byte("packer"), 0644); err != nil {
+		t.Fatalf("shouldn't have error: %v", err)
+	}
+
+	dst := t.TempDir()
+	if err := comm.UploadDir(dst, src, nil); err != nil {
+		t.Fatalf("shouldn't have error: %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(dst, "src", "sub", "file"))
+	if err != nil || string(content) != "packer" {
+		t.Fatalf("invalid uploaded file: %q, %v", content, err)
+	}
+	if info, err := os.Stat(filepath.Join(dst, "src", "sub", "empty")); err != nil || !info.IsDir() {
+		t.Fatalf("empty directory should be uploaded: %v", err)
+	}
+}
+
+func TestStepConnectTat_waitForAgent(t *testing.T) {
+	comm := testTatCommunicator(t)
+
+	step := &stepConnectTat{AgentTimeout: time.Minute}
+	agent, err := step.waitForAgent(context.Background(), comm.client, "ins-qwer1234")
+	if err != nil {
+		t.Fatalf("shouldn't have error: %v", err)
+	}
+	if *agent.Environment != "Linux" {
+		t.Fatalf("invalid agent environment: %s", *agent.Environment)
+	}
+}
@@ -0,0 +1,132 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package cvm
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"sync"
+	"testing"
+)
+
+// fakeCvm is a fake of the tencentcloud apis, answering every action with its
+// handler and remembering the calls made.
+type fakeCvm struct {
+	mu       sync.Mutex
+	handlers map[string]fakeCvmHandler
+	calls    []fakeCvmCall
+}
+
+// fakeCvmCall is a call of an action of the api.
+type fakeCvmCall struct {
+	Action string
+	Region string
+	Body   []byte
+}
+
+// decode unmarshals the parameters of the call into v.
+func (c fakeCvmCall) decode(v interface{}) {
+	_ = json.Unmarshal(c.Body, v)
+}
+
+// fakeCvmHandler returns the response of a call, nil for an empty one. The
+// handlers run one at a time.
+type fakeCvmHandler func(call fakeCvmCall) interface{}
+
+// fakeCvmError is answered as an error of the api.
+type fakeCvmError struct {
+	Code    string
+	Message string
+}
+
+// newFakeCvm starts a fake serving the handlers, and returns it with an
+// access config reaching it.
+func newFakeCvm(t *testing.T, handlers map[string]fakeCvmHandler) (*fakeCvm, *TencentCloudAccessConfig) {
+	f := &fakeCvm{handlers: handlers}
+	server := httptest.NewServer(f)
+	t.Cleanup(server.Close)
+
+	return f, &TencentCloudAccessConfig{
+		SecretId:    "secret-id",
+		SecretKey:   "secret-key",
+		Region:      "ap-guangzhou",
+		CvmEndpoint: server.URL,
+		VpcEndpoint: server.URL,
+		CbsEndpoint: server.URL,
+		TagEndpoint: server.URL,
+		StsEndpoint: server.URL,
+	}
+}
+
+func (f *fakeCvm) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	call := fakeCvmCall{
+		Action: r.Header.Get("X-TC-Action"),
+		Region: r.Header.Get("X-TC-Region"),
+		Body:   body,
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.calls = append(f.calls, call)
+	handler, ok := f.handlers[call.Action]
+	if !ok {
+		http.Error(w, "unknown action "+call.Action, http.StatusBadRequest)
+		return
+	}
+
+	var response interface{} = map[string]interface{}{}
+	switch result := handler(call).(type) {
+	case nil:
+	case fakeCvmError:
+		response = map[string]interface{}{
+			"Error":     map[string]string{"Code": result.Code, "Message": result.Message},
+			"RequestId": "fake-request",
+		}
+	default:
+		response = result
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"Response": response})
+}
+
+// regions returns the sorted regions action was called in, once per call.
+func (f *fakeCvm) regions(action string) []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var regions []string
+	for _, call := range f.calls {
+		if call.Action == action {
+			regions = append(regions, call.Region)
+		}
+	}
+	sort.Strings(regions)
+
+	return regions
+}
+
+// callsOf returns the calls of action, in the order they were made.
+func (f *fakeCvm) callsOf(action string) []fakeCvmCall {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var calls []fakeCvmCall
+	for _, call := range f.calls {
+		if call.Action == action {
+			calls = append(calls, call)
+		}
+	}
+
+	return calls
+}
@@ -2,6 +2,7 @@
 // SPDX-License-Identifier: MPL-2.0
 
 //go:generate packer-sdc struct-markdown
+//go:generate packer-sdc mapstructure-to-hcl2 -type tencentCloudRegionShareAccounts,tencentCloudRegionImage
 
 package cvm
 
@@ -12,6 +13,23 @@ import (
 	"github.com/hashicorp/packer-plugin-sdk/template/interpolate"
 )
 
+type tencentCloudRegionShareAccounts struct {
+	// The region of the image shared.
+	Region string `mapstructure:"region" required:"true"`
+	// Accounts the image of the region is shared to.
+	Accounts []string `mapstructure:"accounts" required:"true"`
+}
+
+type tencentCloudRegionImage struct {
+	// The region of the image copy.
+	Region string `mapstructure:"region" required:"true"`
+	// The name of the image in the region, instead of `image_name`.
+	ImageName string `mapstructure:"image_name" required:"false"`
+	// The description of the image in the region, instead of
+	// `image_description`.
+	ImageDescription string `mapstructure:"image_description" required:"false"`
+}
+
 type TencentCloudImageConfig struct {
 	// The name you want to create your customize image,
 	// it should be composed of no more than 60 characters, of letters, numbers
@@ -27,10 +45,31 @@ type TencentCloudImageConfig struct {
 	// regions that will be copied to after
 	// your image created.
 	ImageCopyRegions []string `mapstructure:"image_copy_regions" required:"false"`
+	// Keep the build going when the image fails to be copied to some of
+	// `image_copy_regions`, the artifact then only has the images copied
+	// successfully and the images left in the failed regions are deleted.
+	// Default value is `false`.
+	ImageCopyAllowPartial bool `mapstructure:"image_copy_allow_partial" required:"false"`
+	// The name or description of the image copied to a region of
+	// `image_copy_regions`, instead of `image_name` and `image_description`.
+	// Each block allows for the following argument:
+	// -  `region` - The region of the image copy.
+	// -  `image_name` - The name of the image in the region.
+	// -  `image_description` - The description of the image in the region.
+	ImageRegionOverrides []tencentCloudRegionImage `mapstructure:"image_region_overrides" required:"false"`
 	// accounts that will be shared to
 	// after your image created.
+	// The image is shared in the region it is created in, and in every region
+	// it is copied to.
 	ImageShareAccounts []string `mapstructure:"image_share_accounts" required:"false"`
-	// Key/value pair tags that will be applied to the resulting image.
+	// Accounts the image of a region is shared to, instead of
+	// `image_share_accounts`. Each block allows for the following argument:
+	// -  `region` - The region of the image.
+	// -  `accounts` - Accounts the image of the region is shared to, an empty
+	//    list disables sharing in the region.
+	ImageRegionShareAccounts []tencentCloudRegionShareAccounts `mapstructure:"image_region_share_accounts" required:"false"`
+	// Key/value pair tags that will be applied to the resulting image, and to
+	// its copies in `image_copy_regions`.
 	ImageTags      map[string]string `mapstructure:"image_tags" required:"false"`
 	skipValidation bool
 }
@@ -70,6 +109,37 @@ func (cf *TencentCloudImageConfig) Prepare(ctx *interpolate.Context) []error {
 		cf.ImageCopyRegions = regions
 	}
 
+	overrideRegions := make(map[string]struct{})
+	for i, override := range cf.ImageRegionOverrides {
+		if override.Region == "" {
+			errs = append(errs, fmt.Errorf("image_region_overrides[%d] region must be specified", i))
+			continue
+		}
+		if _, ok := overrideRegions[override.Region]; ok {
+			errs = append(errs, fmt.Errorf("image_region_overrides has region(%s) more than once", override.Region))
+		}
+		overrideRegions[override.Region] = struct{}{}
+
+		if utf8.RuneCountInString(override.ImageName) > 60 {
+			errs = append(errs, fmt.Errorf("image_region_overrides[%d] image_name length should not exceed 60 characters", i))
+		}
+		if utf8.RuneCountInString(override.ImageDescription) > 60 {
+			errs = append(errs, fmt.Errorf("image_region_overrides[%d] image_description length should not exceed 60 characters", i))
+		}
+	}
+
+	shareRegions := make(map[string]struct{})
+	for i, share := range cf.ImageRegionShareAccounts {
+		if share.Region == "" {
+			errs = append(errs, fmt.Errorf("image_region_share_accounts[%d] region must be specified", i))
+			continue
+		}
+		if _, ok := shareRegions[share.Region]; ok {
+			errs = append(errs, fmt.Errorf("image_region_share_accounts has region(%s) more than once", share.Region))
+		}
+		shareRegions[share.Region] = struct{}{}
+	}
+
 	if cf.ImageTags == nil {
 		cf.ImageTags = make(map[string]string)
 	}
@@ -80,3 +150,54 @@ func (cf *TencentCloudImageConfig) Prepare(ctx *interpolate.Context) []error {
 
 	return nil
 }
+
+// CheckRegions checks the regions of image_region_overrides are regions the
+// image is copied to, and the ones of image_region_share_accounts have an
+// image, being either the region the image is built in or one of
+// image_copy_regions.
+func (cf *TencentCloudImageConfig) CheckRegions(buildRegion string) []error {
+	var errs []error
+
+	imageRegions := map[string]struct{}{buildRegion: {}}
+	for _, region := range cf.ImageCopyRegions {
+		imageRegions[region] = struct{}{}
+	}
+
+	for i, override := range cf.ImageRegionOverrides {
+		if _, ok := imageRegions[override.Region]; override.Region != "" && (!ok || override.Region == buildRegion) {
+			errs = append(errs, fmt.Errorf("image_region_overrides[%d] region(%s) is not copied to, it must be "+
+				"one of image_copy_regions other than the build region", i, override.Region))
+		}
+	}
+
+	for i, share := range cf.ImageRegionShareAccounts {
+		if _, ok := imageRegions[share.Region]; share.Region != "" && !ok {
+			errs = append(errs, fmt.Errorf("image_region_share_accounts[%d] region(%s) has no image, it must be "+
+				"the build region or one of image_copy_regions", i, share.Region))
+		}
+	}
+
+	return errs
+}
+
+// RegionShareAccounts returns the accounts the image of every region in
+// image_region_share_accounts is shared to.
+func (cf *TencentCloudImageConfig) RegionShareAccounts() map[string][]string {
+	accounts := make(map[string][]string, len(cf.ImageRegionShareAccounts))
+	for _, share := range cf.ImageRegionShareAccounts {
+		accounts[share.Region] = share.Accounts
+	}
+
+	return accounts
+}
+
+// RegionImages returns the name and description overrides of every region in
+// image_region_overrides.
+func (cf *TencentCloudImageConfig) RegionImages() map[string]tencentCloudRegionImage {
+	images := make(map[string]tencentCloudRegionImage, len(cf.ImageRegionOverrides))
+	for _, override := range cf.ImageRegionOverrides {
+		images[override.Region] = override
+	}
+
+	return images
+}
@@ -0,0 +1,60 @@
+// Code generated by "packer-sdc mapstructure-to-hcl2"; DO NOT EDIT.
+
+package cvm
+
+import (
+	"github.com/hashicorp/hcl/v2/hcldec"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// FlattencentCloudRegionImage is an auto-generated flat version of tencentCloudRegionImage.
+// Where the contents of a field with a `mapstructure:,squash` tag are bubbled up.
+type FlattencentCloudRegionImage struct {
+	Region           *string `mapstructure:"region" required:"true" cty:"region" hcl:"region"`
+	ImageName        *string `mapstructure:"image_name" required:"false" cty:"image_name" hcl:"image_name"`
+	ImageDescription *string `mapstructure:"image_description" required:"false" cty:"image_description" hcl:"image_description"`
+}
+
+// FlatMapstructure returns a new FlattencentCloudRegionImage.
+// FlattencentCloudRegionImage is an auto-generated flat version of tencentCloudRegionImage.
+// Where the contents a fields with a `mapstructure:,squash` tag are bubbled up.
+func (*tencentCloudRegionImage) FlatMapstructure() interface{ HCL2Spec() map[string]hcldec.Spec } {
+	return new(FlattencentCloudRegionImage)
+}
+
+// HCL2Spec returns the hcl spec of a tencentCloudRegionImage.
+// This spec is used by HCL to read the fields of tencentCloudRegionImage.
+// The decoded values from this spec will then be applied to a FlattencentCloudRegionImage.
+func (*FlattencentCloudRegionImage) HCL2Spec() map[string]hcldec.Spec {
+	s := map[string]hcldec.Spec{
+		"region":            &hcldec.AttrSpec{Name: "region", Type: cty.String, Required: false},
+		"image_name":        &hcldec.AttrSpec{Name: "image_name", Type: cty.String, Required: false},
+		"image_description": &hcldec.AttrSpec{Name: "image_description", Type: cty.String, Required: false},
+	}
+	return s
+}
+
+// FlattencentCloudRegionShareAccounts is an auto-generated flat version of tencentCloudRegionShareAccounts.
+// Where the contents of a field with a `mapstructure:,squash` tag are bubbled up.
+type FlattencentCloudRegionShareAccounts struct {
+	Region   *string  `mapstructure:"region" required:"true" cty:"region" hcl:"region"`
+	Accounts []string `mapstructure:"accounts" required:"true" cty:"accounts" hcl:"accounts"`
+}
+
+// FlatMapstructure returns a new FlattencentCloudRegionShareAccounts.
+// FlattencentCloudRegionShareAccounts is an auto-generated flat version of tencentCloudRegionShareAccounts.
+// Where the contents a fields with a `mapstructure:,squash` tag are bubbled up.
+func (*tencentCloudRegionShareAccounts) FlatMapstructure() interface{ HCL2Spec() map[string]hcldec.Spec } {
+	return new(FlattencentCloudRegionShareAccounts)
+}
+
+// HCL2Spec returns the hcl spec of a tencentCloudRegionShareAccounts.
+// This spec is used by HCL to read the fields of tencentCloudRegionShareAccounts.
+// The decoded values from this spec will then be applied to a FlattencentCloudRegionShareAccounts.
+func (*FlattencentCloudRegionShareAccounts) HCL2Spec() map[string]hcldec.Spec {
+	s := map[string]hcldec.Spec{
+		"region":   &hcldec.AttrSpec{Name: "region", Type: cty.String, Required: false},
+		"accounts": &hcldec.AttrSpec{Name: "accounts", Type: cty.List(cty.String), Required: false},
+	}
+	return s
+}
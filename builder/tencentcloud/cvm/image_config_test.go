@@ -42,3 +42,62 @@ func TestTencentCloudImageConfig_Prepare(t *testing.T) {
 		t.Fatalf("shouldn't have err:%v", err)
 	}
 }
+
+func TestTencentCloudImageConfig_RegionShareAccounts(t *testing.T) {
+	cf := &TencentCloudImageConfig{
+		ImageName: "foo",
+		ImageRegionShareAccounts: []tencentCloudRegionShareAccounts{
+			{Region: "ap-shanghai", Accounts: []string{"100000000001"}},
+		},
+	}
+	if err := cf.Prepare(nil); err != nil {
+		t.Fatalf("shouldn't have err: %v", err)
+	}
+	if accounts := cf.RegionShareAccounts()["ap-shanghai"]; len(accounts) != 1 || accounts[0] != "100000000001" {
+		t.Fatalf("invalid region share accounts: %v", cf.RegionShareAccounts())
+	}
+
+	cf.ImageRegionShareAccounts = append(cf.ImageRegionShareAccounts, tencentCloudRegionShareAccounts{
+		Region: "ap-shanghai",
+	})
+	if err := cf.Prepare(nil); err == nil {
+		t.Fatal("should have err: duplicated region")
+	}
+	cf.ImageRegionShareAccounts = cf.ImageRegionShareAccounts[:1]
+	if errs := cf.CheckRegions("ap-shanghai"); len(errs) != 0 {
+		t.Fatalf("shouldn't have err: %v", errs)
+	}
+	if errs := cf.CheckRegions("ap-guangzhou"); len(errs) == 0 {
+		t.Fatal("should have err: region has no image")
+	}
+	cf.ImageCopyRegions = []string{"ap-shanghai"}
+	if errs := cf.CheckRegions("ap-guangzhou"); len(errs) != 0 {
+		t.Fatalf("shouldn't have err: %v", errs)
+	}
+}
+
+func TestTencentCloudImageConfig_RegionOverrides(t *testing.T) {
+	cf := &TencentCloudImageConfig{
+		ImageName:        "foo",
+		ImageCopyRegions: []string{"ap-guangzhou", "ap-shanghai"},
+		ImageRegionOverrides: []tencentCloudRegionImage{
+			{Region: "ap-shanghai", ImageName: "foo-shanghai"},
+		},
+	}
+	if err := cf.Prepare(nil); err != nil {
+		t.Fatalf("shouldn't have err: %v", err)
+	}
+	if errs := cf.CheckRegions("ap-guangzhou"); len(errs) != 0 {
+		t.Fatalf("shouldn't have err: %v", errs)
+	}
+
+	cf.ImageRegionOverrides[0].Region = "ap-guangzhou"
+	if errs := cf.CheckRegions("ap-guangzhou"); len(errs) == 0 {
+		t.Fatal("should have err: the build region is not copied to")
+	}
+
+	cf.ImageRegionOverrides[0].Region = "ap-beijing"
+	if errs := cf.CheckRegions("ap-guangzhou"); len(errs) == 0 {
+		t.Fatal("should have err: region is not one of image_copy_regions")
+	}
+}
@@ -2,26 +2,118 @@
 // SPDX-License-Identifier: MPL-2.0
 
 //go:generate packer-sdc struct-markdown
-//go:generate packer-sdc mapstructure-to-hcl2 -type tencentCloudDataDisk
+//go:generate packer-sdc mapstructure-to-hcl2 -type tencentCloudDataDisk,tencentCloudImageDataDisk,tencentCloudSourceImageFilter,tencentCloudResourceFilter
 
 package cvm
 
 import (
 	"fmt"
+	"net"
 	"os"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/hashicorp/packer-plugin-sdk/communicator"
 	"github.com/hashicorp/packer-plugin-sdk/template/config"
 	"github.com/hashicorp/packer-plugin-sdk/template/interpolate"
 	"github.com/hashicorp/packer-plugin-sdk/uuid"
 	"github.com/pkg/errors"
+	"github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common"
+	cvm "github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/cvm/v20170312"
+	vpc "github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/vpc/v20170312"
 )
 
 type tencentCloudDataDisk struct {
 	DiskType   string `mapstructure:"disk_type"`
 	DiskSize   int64  `mapstructure:"disk_size"`
 	SnapshotId string `mapstructure:"disk_snapshot_id"`
+	Encrypt    bool   `mapstructure:"encrypt"`
+	KmsKeyId   string `mapstructure:"kms_key_id"`
+
+	SourceDiskIndex       *int   `mapstructure:"source_disk_index"`
+	ThroughputPerformance int64  `mapstructure:"throughput_performance"`
+	BurstPerformance      bool   `mapstructure:"burst_performance"`
+	DeleteWithInstance    *bool  `mapstructure:"delete_with_instance"`
+	MountTag              string `mapstructure:"mount_tag"`
+}
+
+type tencentCloudImageDataDisk struct {
+	// Index of the data disk of the instance, starting from 0. The data
+	// disks of the source image come first, then the others in the order of
+	// `data_disks`.
+	Index *int `mapstructure:"index"`
+	// Size of the data disk in GB.
+	DiskSize int64 `mapstructure:"disk_size"`
+	// `mount_tag` of the data disk in `data_disks`.
+	MountTag string `mapstructure:"mount_tag"`
+}
+
+func (d *tencentCloudImageDataDisk) match(index int, disk *cvm.DataDisk, mountTag string) bool {
+	switch {
+	case d.Index != nil:
+		return *d.Index == index
+	case d.DiskSize != 0:
+		return disk.DiskSize != nil && *disk.DiskSize == d.DiskSize
+	default:
+		return d.MountTag == mountTag
+	}
+}
+
+type tencentCloudSourceImageFilter struct {
+	// Type of the source image, values can be `PRIVATE_IMAGE`,
+	// `PUBLIC_IMAGE` or `SHARED_IMAGE`.
+	ImageType string `mapstructure:"image_type"`
+	// Platform of the source image, such as `TencentOS`, `CentOS` or `Ubuntu`.
+	Platform string `mapstructure:"platform"`
+	// Key/value pair tags the source image must carry.
+	Tags map[string]string `mapstructure:"tags"`
+}
+
+func (f *tencentCloudSourceImageFilter) Empty() bool {
+	return f.ImageType == "" && f.Platform == "" && len(f.Tags) == 0
+}
+
+func (f *tencentCloudSourceImageFilter) describeFilters() []*cvm.Filter {
+	return ImageFilters(f.ImageType, f.Platform, f.Tags)
+}
+
+type tencentCloudResourceFilter struct {
+	// Exact name of the resource.
+	Name string `mapstructure:"name"`
+	// Key/value pair tags the resource must carry.
+	Tags map[string]string `mapstructure:"tags"`
+}
+
+func (f *tencentCloudResourceFilter) Empty() bool {
+	return f.Name == "" && len(f.Tags) == 0
+}
+
+// describeFilters builds the vpc api filters, nameFilter being the name of
+// the filter on the resource name, such as `vpc-name`.
+func (f *tencentCloudResourceFilter) describeFilters(nameFilter string) []*vpc.Filter {
+	var filters []*vpc.Filter
+	addFilter := func(name, value string) {
+		filters = append(filters, &vpc.Filter{
+			Name:   common.StringPtr(name),
+			Values: []*string{common.StringPtr(value)},
+		})
+	}
+
+	if f.Name != "" {
+		addFilter(nameFilter, f.Name)
+	}
+	for k, v := range f.Tags {
+		addFilter(fmt.Sprintf("tag:%s", k), v)
+	}
+
+	return filters
+}
+
+// matchName checks the resource name exactly, as the api does a fuzzy
+// match on names.
+func (f *tencentCloudResourceFilter) matchName(name *string) bool {
+	return f.Name == "" || (name != nil && *name == f.Name)
 }
 
 type TencentCloudRunConfig struct {
@@ -33,48 +125,133 @@ type TencentCloudRunConfig struct {
 	SourceImageId string `mapstructure:"source_image_id" required:"false"`
 	// The base image name of Image you want to create your
 	// customized image from.Conflict with SourceImageId.
+	// It is used as a regex, if several images match it, the build fails
+	// unless `source_image_most_recent` is set.
 	SourceImageName string `mapstructure:"source_image_name" required:"false"`
+	// Use the newest created image when several images match the source
+	// image settings. Default value is `false`.
+	SourceImageMostRecent bool `mapstructure:"source_image_most_recent" required:"false"`
+	// Filters used to select the source image, conflict with SourceImageId.
+	// It can be combined with `source_image_name`, which is then matched
+	// against the filtered images.
+	// The filter allows for the following argument:
+	// -  `image_type` - Type of the image. Valid choices: `PRIVATE_IMAGE`, `PUBLIC_IMAGE` and `SHARED_IMAGE`.
+	// -  `platform` - Platform of the image, such as `TencentOS` or `Ubuntu`.
+	// -  `tags` - Key/value pair tags the image must carry.
+	SourceImageFilter tencentCloudSourceImageFilter `mapstructure:"source_image_filter" required:"false"`
 	// Charge type of cvm, values can be `POSTPAID_BY_HOUR` (default) `SPOTPAID`
 	InstanceChargeType string `mapstructure:"instance_charge_type" required:"false"`
+	// The maximum hourly price you are willing to pay for a spot instance.
+	// Setting it implies `instance_charge_type` `SPOTPAID`. If not set,
+	// the spot instance is charged at the current market price.
+	SpotPrice string `mapstructure:"spot_price" required:"false"`
+	// The spot instance request type, only `one-time` is supported for now,
+	// which is the default value.
+	SpotInstanceType string `mapstructure:"spot_instance_type" required:"false"`
+	// Launch a `POSTPAID_BY_HOUR` instance when the spot instance can't be
+	// launched, because spot capacity is sold out, the price is too low or
+	// the instance is reclaimed before it is running. Default value is `false`.
+	SpotFallbackToPostpaid bool `mapstructure:"spot_fallback_to_postpaid" required:"false"`
 	// The instance type your cvm will be launched by.
 	// You should reference [Instance Type](https://intl.cloud.tencent.com/document/product/213/11518)
 	// for parameter taking.
 	InstanceType string `mapstructure:"instance_type" required:"true"`
+	// Additional instance types your cvm may be launched by. Instance
+	// types are tried in order, after `instance_type`, when they are sold
+	// out in every zone.
+	InstanceTypes []string `mapstructure:"instance_types" required:"false"`
 	// Instance name.
 	InstanceName string `mapstructure:"instance_name" required:"false"`
 	// Root disk type your cvm will be launched by, default is `CLOUD_PREMIUM`. you could
 	// reference [Disk Type](https://intl.cloud.tencent.com/document/product/213/15753#SystemDisk)
 	// for parameter taking.
 	DiskType string `mapstructure:"disk_type" required:"false"`
-	// Root disk size your cvm will be launched by. values range(in GB):
+	// Root disk size your cvm will be launched by, default is `50`, or the
+	// system disk size of the source image when it is larger. It must not be
+	// smaller than the system disk of the source image. values range(in GB):
 	// - LOCAL_BASIC: 50
-	// - Other: 50 ~ 1000 (need whitelist if > 50)
+	// - CLOUD_BASIC: 20 ~ 500
+	// - Other: 20 ~ 2048
 	DiskSize int64 `mapstructure:"disk_size" required:"false"`
+	// Grow `disk_size` to the system disk size of the source image when it
+	// is smaller, instead of failing. Default value is `false`.
+	DiskAutoGrow bool `mapstructure:"disk_auto_grow" required:"false"`
+	// Require the root disk to be encrypted, so that the system disk
+	// snapshot of the image is encrypted too. Default value is `false`.
+	// RunInstances can't encrypt the root disk, which is encrypted only when
+	// the system disk snapshot of the source image is, so the build fails
+	// before launching the instance if it is not. There is no kms key
+	// option for the root disk, it uses the key of that snapshot.
+	DiskEncrypt bool `mapstructure:"disk_encrypt" required:"false"`
 	// Add one or more data disks to the instance before creating the image.
-	// Note that if the source image has data disk snapshots, this argument
-	// will be ignored, and the running instance will use source image data
-	// disk settings, in such case, `disk_type` argument will be used as disk
-	// type for all data disks, and each data disk size will use the origin
-	// value in source image.
+	// Note that if the source image has data disk snapshots, the instance
+	// always has them as data disks, by default with `disk_type` as type and
+	// the snapshot size. A data disk whose `source_disk_index` or
+	// `disk_snapshot_id` matches one of them overrides its settings, the
+	// other data disks are added to them.
 	// The data disks allow for the following argument:
-	// -  `disk_type` - Type of the data disk. Valid choices: `CLOUD_BASIC`, `CLOUD_PREMIUM` and `CLOUD_SSD`.
-	// -  `disk_size` - Size of the data disk.
+	// -  `disk_type` - Type of the data disk. Valid choices: `CLOUD_BASIC`,
+	//    `CLOUD_PREMIUM`, `CLOUD_SSD`, `CLOUD_BSSD`, `CLOUD_HSSD` and
+	//    `CLOUD_TSSD`.
+	// -  `disk_size` - Size of the data disk. The size of a source image data
+	//    disk can only grow.
 	// -  `disk_snapshot_id` - Id of the snapshot for a data disk.
+	// -  `source_disk_index` - Index of the source image data disk this data
+	//    disk overrides, starting from 0.
+	// -  `throughput_performance` - Extra throughput of the data disk in
+	//    MB/s, for `CLOUD_TSSD` and `CLOUD_HSSD` disks.
+	// -  `burst_performance` - Enable the burst performance of the data disk.
+	// -  `delete_with_instance` - Delete the data disk when the instance is
+	//    terminated, default is `true`. It can only be `false` for the data
+	//    disks included into the image.
+	// -  `encrypt` - Encrypt the data disk, so that its snapshot in the image
+	//    is encrypted too.
+	// -  `kms_key_id` - Id of the kms key the data disk is encrypted with,
+	//    default is the cbs default key. Requires `encrypt`.
+	// -  `mount_tag` - A name for the data disk, used to select it in
+	//    `image_data_disks`.
 	DataDisks []tencentCloudDataDisk `mapstructure:"data_disks"`
+	// Data disks of the instance included into the created image, default
+	// is all of them. Each selector matches the data disks with either:
+	// -  `index` - Index of the data disk, starting from 0. The data disks of
+	//    the source image come first, then the others in the order of
+	//    `data_disks`.
+	// -  `disk_size` - Size of the data disk in GB.
+	// -  `mount_tag` - `mount_tag` of the data disk in `data_disks`.
+	ImageDataDisks []tencentCloudImageDataDisk `mapstructure:"image_data_disks" required:"false"`
+	// Only include the system disk into the created image, conflict with
+	// `image_data_disks`.
+	ImageSystemDiskOnly bool `mapstructure:"image_system_disk_only" required:"false"`
 	// Specify vpc your cvm will be launched by.
 	VpcId string `mapstructure:"vpc_id" required:"false"`
+	// Filters used to select an existing vpc, conflict with `vpc_id`.
+	// The build fails unless exactly one vpc matches.
+	// The filter allows for the following argument:
+	// -  `name` - Exact name of the vpc.
+	// -  `tags` - Key/value pair tags the vpc must carry.
+	VpcFilter tencentCloudResourceFilter `mapstructure:"vpc_filter" required:"false"`
 	// Specify vpc name you will create. if `vpc_id` is not set, Packer will
 	// create a vpc for you named this parameter.
 	VpcName string `mapstructure:"vpc_name" required:"false"`
-	// Specify subnet your cvm will be launched by.
+	// Specify subnet your cvm will be launched by. If an existing vpc is
+	// used but neither `subnet_id`, `subnet_filter` nor `subnect_cidr_block`
+	// is set, the existing subnet of the vpc with the most available ip
+	// addresses in each zone is used.
 	SubnetId string `mapstructure:"subnet_id" required:"false"`
+	// Filters used to select an existing subnet of the vpc, conflict with
+	// `subnet_id`. The build fails unless exactly one subnet matches.
+	// The filter allows for the following argument:
+	// -  `name` - Exact name of the subnet.
+	// -  `tags` - Key/value pair tags the subnet must carry.
+	SubnetFilter tencentCloudResourceFilter `mapstructure:"subnet_filter" required:"false"`
 	// Specify subnet name you will create. if `subnet_id` is not set, Packer will
 	// create a subnet for you named this parameter.
 	SubnetName string `mapstructure:"subnet_name" required:"false"`
 	// Specify cider block of the vpc you will create if vpc_id not set
 	CidrBlock string `mapstructure:"cidr_block" required:"false"` // 10.0.0.0/16(default), 172.16.0.0/12, 192.168.0.0/16
 	// Specify cider block of the subnet you will create if
-	// subnet_id not set
+	// subnet_id not set. The subnet is created in the first zone offering
+	// the instance types, which the cvm is launched in.
 	SubnectCidrBlock string `mapstructure:"subnect_cidr_block" required:"false"`
 	// Internet charge type of cvm, values can be TRAFFIC_POSTPAID_BY_HOUR, BANDWIDTH_POSTPAID_BY_HOUR, BANDWIDTH_PACKAGE
 	InternetChargeType string `mapstructure:"internet_charge_type" required:"false"`
@@ -85,8 +262,31 @@ type TencentCloudRunConfig struct {
 	BandwidthPackageId string `mapstructure:"bandwidth_package_id" required:"false"`
 	// Specify securitygroup your cvm will be launched by.
 	SecurityGroupId string `mapstructure:"security_group_id" required:"false"`
+	// Filters used to select an existing securitygroup, conflict with
+	// `security_group_id`. The build fails unless exactly one securitygroup
+	// matches.
+	// The filter allows for the following argument:
+	// -  `name` - Exact name of the securitygroup.
+	// -  `tags` - Key/value pair tags the securitygroup must carry.
+	SecurityGroupFilter tencentCloudResourceFilter `mapstructure:"security_group_filter" required:"false"`
+	// Additional existing securitygroups your cvm will be launched with,
+	// along with `security_group_id`, `security_group_filter` or the
+	// temporary securitygroup. These securitygroups are never deleted.
+	SecurityGroupIds []string `mapstructure:"security_group_ids" required:"false"`
 	// Specify security name you will create if security_group_id not set.
 	SecurityGroupName string `mapstructure:"security_group_name" required:"false"`
+	// A list of IPv4 CIDR blocks allowed to reach the communicator port
+	// of the instance through the temporary securitygroup created when
+	// `security_group_id` is not set. Default value is `["0.0.0.0/0"]`.
+	TemporarySecurityGroupSourceCidrs []string `mapstructure:"temporary_security_group_source_cidrs" required:"false"`
+	// Only allow the public ip of the machine running Packer to reach the
+	// communicator port of the instance through the temporary securitygroup.
+	// The ip is detected when the securitygroup is created. Conflict with
+	// `temporary_security_group_source_cidrs`. Default value is `false`.
+	TemporarySecurityGroupSourcePublicIp bool `mapstructure:"temporary_security_group_source_public_ip" required:"false"`
+	// A list of IPv4 CIDR blocks the instance is allowed to reach through
+	// the temporary securitygroup. Default value is `["0.0.0.0/0"]`.
+	TemporarySecurityGroupEgressCidrs []string `mapstructure:"temporary_security_group_egress_cidrs" required:"false"`
 	// userdata.
 	UserData string `mapstructure:"user_data" required:"false"`
 	// userdata file.
@@ -107,31 +307,94 @@ type TencentCloudRunConfig struct {
 	// Communicator settings
 	Comm         communicator.Config `mapstructure:",squash"`
 	SSHPrivateIp bool                `mapstructure:"ssh_private_ip"`
+	// The user commands are run as when `communicator` is `tat`. The
+	// default user of the tat agent is used if not set, `root` on Linux
+	// and `System` on Windows.
+	TatUsername string `mapstructure:"tat_username" required:"false"`
+	// The amount of time a command may run when `communicator` is `tat`,
+	// up to `24h`. Default value is `1h`.
+	TatCommandTimeout time.Duration `mapstructure:"tat_command_timeout" required:"false"`
+	// The amount of time to wait for the tat agent of the instance to be
+	// online when `communicator` is `tat`. Default value is `10m`.
+	TatAgentTimeout time.Duration `mapstructure:"tat_agent_timeout" required:"false"`
 }
 
 var ValidCBSType = []string{
 	"LOCAL_BASIC", "LOCAL_SSD", "CLOUD_BASIC", "CLOUD_SSD", "CLOUD_PREMIUM",
+	"CLOUD_BSSD", "CLOUD_HSSD", "CLOUD_TSSD",
+}
+
+// systemDiskSizeRange returns the minimum and maximum system disk size in GB
+// of a disk type from ValidCBSType.
+func systemDiskSizeRange(diskType string) (int64, int64) {
+	switch diskType {
+	case "LOCAL_BASIC":
+		return 50, 50
+	case "CLOUD_BASIC":
+		return 20, 500
+	default:
+		return 20, 2048
+	}
+}
+
+var ValidImageTypes = []string{
+	"PRIVATE_IMAGE", "PUBLIC_IMAGE", "SHARED_IMAGE",
 }
 
 func (cf *TencentCloudRunConfig) Prepare(ctx *interpolate.Context) []error {
 	packerId := fmt.Sprintf("packer_%s", uuid.TimeOrderedUUID()[:8])
+	useTat := cf.Comm.Type == "tat"
 	if cf.Comm.SSHKeyPairName == "" && cf.Comm.SSHTemporaryKeyPairName == "" &&
-		cf.Comm.SSHPrivateKeyFile == "" && cf.Comm.SSHPassword == "" && cf.Comm.WinRMPassword == "" {
+		cf.Comm.SSHPrivateKeyFile == "" && cf.Comm.SSHPassword == "" && cf.Comm.WinRMPassword == "" && !useTat {
 		//tencentcloud support key pair name length max to 25
 		cf.Comm.SSHTemporaryKeyPairName = packerId
 	}
 
+	// The communicator config knows nothing about tat, which needs no
+	// connection settings, just like none.
+	if useTat {
+		cf.Comm.Type = "none"
+	}
 	errs := cf.Comm.Prepare(ctx)
-	if cf.SourceImageId == "" && cf.SourceImageName == "" {
-		errs = append(errs, errors.New("source_image_id or source_image_name must be specified"))
+	if useTat {
+		cf.Comm.Type = "tat"
+		if cf.TatCommandTimeout == 0 {
+			cf.TatCommandTimeout = time.Hour
+		}
+		if cf.TatAgentTimeout == 0 {
+			cf.TatAgentTimeout = 10 * time.Minute
+		}
+		if cf.TatCommandTimeout < time.Second || cf.TatCommandTimeout > 24*time.Hour {
+			errs = append(errs, fmt.Errorf("specified tat_command_timeout(%s) is invalid, it must be between 1s and 24h",
+				cf.TatCommandTimeout))
+		}
+	} else if cf.TatUsername != "" || cf.TatCommandTimeout != 0 || cf.TatAgentTimeout != 0 {
+		errs = append(errs, errors.New("tat_username, tat_command_timeout and tat_agent_timeout "+
+			"can only be used when communicator is tat"))
+	}
+	if cf.SourceImageId == "" && cf.SourceImageName == "" && cf.SourceImageFilter.Empty() {
+		errs = append(errs, errors.New("source_image_id, source_image_name or source_image_filter must be specified"))
+	}
+
+	if cf.SourceImageId != "" && !cf.SourceImageFilter.Empty() {
+		errs = append(errs, errors.New("only one of source_image_id or source_image_filter can be specified"))
+	}
+
+	if cf.SourceImageFilter.ImageType != "" && !CheckImageType(cf.SourceImageFilter.ImageType) {
+		errs = append(errs, fmt.Errorf("specified source_image_filter image_type(%s) is invalid",
+			cf.SourceImageFilter.ImageType))
 	}
 
 	if cf.SourceImageId != "" && !CheckResourceIdFormat("img", cf.SourceImageId) {
 		errs = append(errs, errors.New("source_image_id wrong format"))
 	}
 
+	if cf.InstanceType == "" && len(cf.InstanceTypes) > 0 {
+		cf.InstanceType = cf.InstanceTypes[0]
+	}
+
 	if cf.InstanceType == "" {
-		errs = append(errs, errors.New("instance_type must be specified"))
+		errs = append(errs, errors.New("instance_type or instance_types must be specified"))
 	}
 
 	if cf.UserData != "" && cf.UserDataFile != "" {
@@ -142,44 +405,180 @@ func (cf *TencentCloudRunConfig) Prepare(ctx *interpolate.Context) []error {
 		}
 	}
 
-	if (cf.VpcId != "" || cf.CidrBlock != "") && cf.SubnetId == "" && cf.SubnectCidrBlock == "" {
+	if cf.VpcId != "" && !cf.VpcFilter.Empty() {
+		errs = append(errs, errors.New("only one of vpc_id or vpc_filter can be specified"))
+	}
+
+	if cf.SubnetId != "" && !cf.SubnetFilter.Empty() {
+		errs = append(errs, errors.New("only one of subnet_id or subnet_filter can be specified"))
+	}
+
+	if cf.SecurityGroupId != "" && !cf.SecurityGroupFilter.Empty() {
+		errs = append(errs, errors.New("only one of security_group_id or security_group_filter can be specified"))
+	}
+
+	for _, id := range cf.SecurityGroupIds {
+		if !CheckResourceIdFormat("sg", id) {
+			errs = append(errs, fmt.Errorf("specified security_group_ids(%s) wrong format", id))
+		}
+	}
+
+	existingVpc := cf.VpcId != "" || !cf.VpcFilter.Empty()
+	existingSubnet := cf.SubnetId != "" || !cf.SubnetFilter.Empty()
+
+	if !existingVpc && cf.CidrBlock != "" && !existingSubnet && cf.SubnectCidrBlock == "" {
 		errs = append(errs, errors.New("if vpc cidr_block is specified, then "+
 			"subnet_cidr_block must also be specified."))
 	}
 
-	if cf.VpcId == "" {
+	if !existingVpc {
 		if cf.VpcName == "" {
 			cf.VpcName = packerId
 		}
 		if cf.CidrBlock == "" {
 			cf.CidrBlock = "10.0.0.0/16"
 		}
-		if cf.SubnetId != "" {
-			errs = append(errs, errors.New("can't set subnet_id without set vpc_id"))
+		if existingSubnet {
+			errs = append(errs, errors.New("can't set subnet_id or subnet_filter without set vpc_id or vpc_filter"))
 		}
 	}
 
-	if cf.SubnetId == "" {
+	if !existingSubnet {
 		if cf.SubnetName == "" {
 			cf.SubnetName = packerId
 		}
-		if cf.SubnectCidrBlock == "" {
+		if cf.SubnectCidrBlock == "" && !existingVpc {
 			cf.SubnectCidrBlock = "10.0.8.0/24"
 		}
 	}
 
-	if cf.SecurityGroupId == "" && cf.SecurityGroupName == "" {
+	if cf.SecurityGroupId == "" && cf.SecurityGroupFilter.Empty() && cf.SecurityGroupName == "" {
 		cf.SecurityGroupName = packerId
 	}
 
+	if cf.SecurityGroupId != "" || !cf.SecurityGroupFilter.Empty() {
+		if len(cf.TemporarySecurityGroupSourceCidrs) > 0 || cf.TemporarySecurityGroupSourcePublicIp ||
+			len(cf.TemporarySecurityGroupEgressCidrs) > 0 {
+			errs = append(errs, errors.New("temporary_security_group_source_cidrs, "+
+				"temporary_security_group_source_public_ip and temporary_security_group_egress_cidrs "+
+				"can't be used with an existing securitygroup"))
+		}
+	}
+
+	if len(cf.TemporarySecurityGroupSourceCidrs) > 0 && cf.TemporarySecurityGroupSourcePublicIp {
+		errs = append(errs, errors.New("only one of temporary_security_group_source_cidrs or "+
+			"temporary_security_group_source_public_ip can be specified"))
+	}
+
+	for _, cidr := range append(cf.TemporarySecurityGroupSourceCidrs, cf.TemporarySecurityGroupEgressCidrs...) {
+		if _, ipNet, err := net.ParseCIDR(cidr); err != nil || ipNet.IP.To4() == nil {
+			errs = append(errs, fmt.Errorf("specified cidr block(%s) is invalid", cidr))
+		}
+	}
+
+	if len(cf.TemporarySecurityGroupSourceCidrs) == 0 && !cf.TemporarySecurityGroupSourcePublicIp {
+		cf.TemporarySecurityGroupSourceCidrs = []string{"0.0.0.0/0"}
+	}
+
+	if len(cf.TemporarySecurityGroupEgressCidrs) == 0 {
+		cf.TemporarySecurityGroupEgressCidrs = []string{"0.0.0.0/0"}
+	}
+
 	if cf.DiskType != "" && !checkDiskType(cf.DiskType) {
 		errs = append(errs, errors.New(fmt.Sprintf("specified disk_type(%s) is invalid", cf.DiskType)))
 	} else if cf.DiskType == "" {
 		cf.DiskType = "CLOUD_PREMIUM"
 	}
 
-	if cf.DiskSize <= 0 {
-		cf.DiskSize = 50
+	// An unset disk_size is left 0, it defaults to 50 or the system disk
+	// size of the source image when it is larger.
+	if cf.DiskSize != 0 && checkDiskType(cf.DiskType) {
+		min, max := systemDiskSizeRange(cf.DiskType)
+		if cf.DiskSize < min || cf.DiskSize > max {
+			errs = append(errs, fmt.Errorf("specified disk_size(%d) is invalid, it should be in range %d ~ %d for %s",
+				cf.DiskSize, min, max, cf.DiskType))
+		}
+	}
+
+	errs = append(errs, checkDiskEncryption("disk", cf.DiskType, cf.DiskEncrypt, "")...)
+	for i, disk := range cf.DataDisks {
+		name := fmt.Sprintf("data_disks[%d]", i)
+		if disk.DiskType != "" && !checkDiskType(disk.DiskType) {
+			errs = append(errs, fmt.Errorf("specified %s disk_type(%s) is invalid", name, disk.DiskType))
+		}
+		if disk.ThroughputPerformance > 0 && disk.DiskType != "" &&
+			disk.DiskType != "CLOUD_HSSD" && disk.DiskType != "CLOUD_TSSD" {
+			errs = append(errs, fmt.Errorf("%s throughput_performance is only supported by CLOUD_HSSD and CLOUD_TSSD", name))
+		}
+		errs = append(errs, checkDiskEncryption(name, disk.DiskType, disk.Encrypt, disk.KmsKeyId)...)
+		if disk.SourceDiskIndex != nil && *disk.SourceDiskIndex < 0 {
+			errs = append(errs, fmt.Errorf("%s source_disk_index(%d) is invalid", name, *disk.SourceDiskIndex))
+		}
+		if disk.SourceDiskIndex != nil && disk.SnapshotId != "" {
+			errs = append(errs, fmt.Errorf("only one of %s source_disk_index or disk_snapshot_id can be specified", name))
+		}
+		if disk.DiskSize < 0 || disk.ThroughputPerformance < 0 {
+			errs = append(errs, fmt.Errorf("specified %s disk_size or throughput_performance is invalid", name))
+		}
+	}
+
+	if cf.ImageSystemDiskOnly && len(cf.ImageDataDisks) > 0 {
+		errs = append(errs, errors.New("image_system_disk_only conflicts with image_data_disks"))
+	}
+	for i, disk := range cf.ImageDataDisks {
+		selectors := 0
+		if disk.Index != nil {
+			selectors++
+		}
+		if disk.DiskSize != 0 {
+			selectors++
+		}
+		if disk.MountTag != "" {
+			selectors++
+		}
+		if selectors != 1 {
+			errs = append(errs, fmt.Errorf("exactly one of index, disk_size or mount_tag must be specified "+
+				"in image_data_disks[%d]", i))
+		} else if (disk.Index != nil && *disk.Index < 0) || disk.DiskSize < 0 {
+			errs = append(errs, fmt.Errorf("image_data_disks[%d] is invalid", i))
+		}
+	}
+	for i, disk := range cf.DataDisks {
+		if disk.DeleteWithInstance != nil && !*disk.DeleteWithInstance && !cf.mayCaptureDataDisk(disk) {
+			errs = append(errs, fmt.Errorf("data_disks[%d] is not captured into the image, "+
+				"delete_with_instance can't be false", i))
+		}
+	}
+
+	if cf.SpotPrice != "" && cf.InstanceChargeType == "" {
+		cf.InstanceChargeType = "SPOTPAID"
+	}
+
+	validInstanceChargeTypes := map[string]int{
+		"POSTPAID_BY_HOUR": 0,
+		"SPOTPAID":         0,
+	}
+
+	if cf.InstanceChargeType != "" {
+		if _, ok := validInstanceChargeTypes[cf.InstanceChargeType]; !ok {
+			errs = append(errs, fmt.Errorf("specified instance_charge_type(%s) is invalid", cf.InstanceChargeType))
+		}
+	}
+
+	if cf.InstanceChargeType == "SPOTPAID" {
+		if cf.SpotPrice != "" {
+			if price, err := strconv.ParseFloat(cf.SpotPrice, 64); err != nil || price <= 0 {
+				errs = append(errs, fmt.Errorf("specified spot_price(%s) is invalid", cf.SpotPrice))
+			}
+		}
+		if cf.SpotInstanceType == "" {
+			cf.SpotInstanceType = "one-time"
+		} else if cf.SpotInstanceType != "one-time" {
+			errs = append(errs, fmt.Errorf("specified spot_instance_type(%s) is invalid", cf.SpotInstanceType))
+		}
+	} else if cf.SpotPrice != "" || cf.SpotInstanceType != "" || cf.SpotFallbackToPostpaid {
+		errs = append(errs, errors.New("spot_price, spot_instance_type and spot_fallback_to_postpaid "+
+			"can only be used when instance_charge_type is SPOTPAID"))
 	}
 
 	validChargeTypes := map[string]int{
@@ -227,6 +626,12 @@ func (cf *TencentCloudRunConfig) Prepare(ctx *interpolate.Context) []error {
 	return errs
 }
 
+// CandidateInstanceTypes returns the instance types your cvm may be
+// launched by, in the order they should be tried.
+func (cf *TencentCloudRunConfig) CandidateInstanceTypes() []string {
+	return uniqueStrings(append([]string{cf.InstanceType}, cf.InstanceTypes...))
+}
+
 func checkDiskType(diskType string) bool {
 	for _, valid := range ValidCBSType {
 		if valid == diskType {
@@ -236,3 +641,102 @@ func checkDiskType(diskType string) bool {
 
 	return false
 }
+
+// mayCaptureDataDisk tells whether the data disk may be included into the
+// image. Disks selected by index depend on the data disks of the source
+// image, so any of them may be.
+func (cf *TencentCloudRunConfig) mayCaptureDataDisk(disk tencentCloudDataDisk) bool {
+	if cf.ImageSystemDiskOnly {
+		return false
+	}
+	if len(cf.ImageDataDisks) == 0 {
+		return true
+	}
+
+	for _, selector := range cf.ImageDataDisks {
+		switch {
+		case selector.Index != nil:
+			return true
+		case selector.DiskSize != 0:
+			if disk.DiskSize == 0 || disk.DiskSize == selector.DiskSize {
+				return true
+			}
+		case selector.MountTag != "" && selector.MountTag == disk.MountTag:
+			return true
+		}
+	}
+
+	return false
+}
+
+// checkSystemDiskSize returns the size of the system disk launched from the
+// source image, which must hold the system disk of the image. A smaller
+// diskSize is grown to it if autoGrow is set, an unset one defaults to 50 or
+// the image system disk size when it is larger.
+func checkSystemDiskSize(image *cvm.Image, diskType string, diskSize int64, autoGrow bool) (int64, error) {
+	var imageSize int64
+	if image.ImageSize != nil {
+		imageSize = *image.ImageSize
+	}
+	for _, snapshot := range image.SnapshotSet {
+		if snapshot.DiskUsage != nil && *snapshot.DiskUsage == "SYSTEM_DISK" &&
+			snapshot.DiskSize != nil && *snapshot.DiskSize > imageSize {
+			imageSize = *snapshot.DiskSize
+		}
+	}
+
+	if diskSize == 0 {
+		diskSize = 50
+		if imageSize > diskSize {
+			diskSize = imageSize
+		}
+		if _, max := systemDiskSizeRange(diskType); diskSize > max {
+			return 0, fmt.Errorf("system disk of source image %s(%dGB) exceeds the maximum size of %s(%dGB)",
+				*image.ImageId, imageSize, diskType, max)
+		}
+		return diskSize, nil
+	}
+
+	if diskSize >= imageSize {
+		return diskSize, nil
+	}
+
+	if !autoGrow {
+		return 0, fmt.Errorf("disk_size(%d) is smaller than the system disk of source image %s(%dGB), "+
+			"set disk_size to at least %d or enable disk_auto_grow", diskSize, *image.ImageId, imageSize, imageSize)
+	}
+
+	if _, max := systemDiskSizeRange(diskType); imageSize > max {
+		return 0, fmt.Errorf("system disk of source image %s(%dGB) exceeds the maximum size of %s(%dGB)",
+			*image.ImageId, imageSize, diskType, max)
+	}
+
+	return imageSize, nil
+}
+
+// checkDiskEncryption checks the encryption settings of a disk, local disks
+// can't be encrypted.
+func checkDiskEncryption(name string, diskType string, encrypt bool, kmsKeyId string) []error {
+	var errs []error
+
+	if kmsKeyId != "" && !encrypt {
+		errs = append(errs, fmt.Errorf("%s kms_key_id can only be used when the disk is encrypted", name))
+	}
+
+	if encrypt && strings.HasPrefix(diskType, "LOCAL_") {
+		errs = append(errs, fmt.Errorf("%s of type %s can't be encrypted", name, diskType))
+	}
+
+	return errs
+}
+
+// CheckImageType check whether image type is valid
+func CheckImageType(imageType string) bool {
+	for _, valid := range ValidImageTypes {
+		if valid == imageType {
+			return true
+		}
+	}
+
+	return false
+}
@@ -10,9 +10,16 @@ import (
 // FlattencentCloudDataDisk is an auto-generated flat version of tencentCloudDataDisk.
 // Where the contents of a field with a `mapstructure:,squash` tag are bubbled up.
 type FlattencentCloudDataDisk struct {
-	DiskType   *string `mapstructure:"disk_type" cty:"disk_type" hcl:"disk_type"`
-	DiskSize   *int64  `mapstructure:"disk_size" cty:"disk_size" hcl:"disk_size"`
-	SnapshotId *string `mapstructure:"disk_snapshot_id" cty:"disk_snapshot_id" hcl:"disk_snapshot_id"`
+	DiskType              *string `mapstructure:"disk_type" cty:"disk_type" hcl:"disk_type"`
+	DiskSize              *int64  `mapstructure:"disk_size" cty:"disk_size" hcl:"disk_size"`
+	SnapshotId            *string `mapstructure:"disk_snapshot_id" cty:"disk_snapshot_id" hcl:"disk_snapshot_id"`
+	Encrypt               *bool   `mapstructure:"encrypt" cty:"encrypt" hcl:"encrypt"`
+	KmsKeyId              *string `mapstructure:"kms_key_id" cty:"kms_key_id" hcl:"kms_key_id"`
+	SourceDiskIndex       *int    `mapstructure:"source_disk_index" cty:"source_disk_index" hcl:"source_disk_index"`
+	ThroughputPerformance *int64  `mapstructure:"throughput_performance" cty:"throughput_performance" hcl:"throughput_performance"`
+	BurstPerformance      *bool   `mapstructure:"burst_performance" cty:"burst_performance" hcl:"burst_performance"`
+	DeleteWithInstance    *bool   `mapstructure:"delete_with_instance" cty:"delete_with_instance" hcl:"delete_with_instance"`
+	MountTag              *string `mapstructure:"mount_tag" cty:"mount_tag" hcl:"mount_tag"`
 }
 
 // FlatMapstructure returns a new FlattencentCloudDataDisk.
@@ -27,9 +34,95 @@ func (*tencentCloudDataDisk) FlatMapstructure() interface{ HCL2Spec() map[string
 // The decoded values from this spec will then be applied to a FlattencentCloudDataDisk.
 func (*FlattencentCloudDataDisk) HCL2Spec() map[string]hcldec.Spec {
 	s := map[string]hcldec.Spec{
-		"disk_type":        &hcldec.AttrSpec{Name: "disk_type", Type: cty.String, Required: false},
-		"disk_size":        &hcldec.AttrSpec{Name: "disk_size", Type: cty.Number, Required: false},
-		"disk_snapshot_id": &hcldec.AttrSpec{Name: "disk_snapshot_id", Type: cty.String, Required: false},
+		"disk_type":              &hcldec.AttrSpec{Name: "disk_type", Type: cty.String, Required: false},
+		"disk_size":              &hcldec.AttrSpec{Name: "disk_size", Type: cty.Number, Required: false},
+		"disk_snapshot_id":       &hcldec.AttrSpec{Name: "disk_snapshot_id", Type: cty.String, Required: false},
+		"encrypt":                &hcldec.AttrSpec{Name: "encrypt", Type: cty.Bool, Required: false},
+		"kms_key_id":             &hcldec.AttrSpec{Name: "kms_key_id", Type: cty.String, Required: false},
+		"source_disk_index":      &hcldec.AttrSpec{Name: "source_disk_index", Type: cty.Number, Required: false},
+		"throughput_performance": &hcldec.AttrSpec{Name: "throughput_performance", Type: cty.Number, Required: false},
+		"burst_performance":      &hcldec.AttrSpec{Name: "burst_performance", Type: cty.Bool, Required: false},
+		"delete_with_instance":   &hcldec.AttrSpec{Name: "delete_with_instance", Type: cty.Bool, Required: false},
+		"mount_tag":              &hcldec.AttrSpec{Name: "mount_tag", Type: cty.String, Required: false},
+	}
+	return s
+}
+
+// FlattencentCloudImageDataDisk is an auto-generated flat version of tencentCloudImageDataDisk.
+// Where the contents of a field with a `mapstructure:,squash` tag are bubbled up.
+type FlattencentCloudImageDataDisk struct {
+	Index    *int    `mapstructure:"index" cty:"index" hcl:"index"`
+	DiskSize *int64  `mapstructure:"disk_size" cty:"disk_size" hcl:"disk_size"`
+	MountTag *string `mapstructure:"mount_tag" cty:"mount_tag" hcl:"mount_tag"`
+}
+
+// FlatMapstructure returns a new FlattencentCloudImageDataDisk.
+// FlattencentCloudImageDataDisk is an auto-generated flat version of tencentCloudImageDataDisk.
+// Where the contents a fields with a `mapstructure:,squash` tag are bubbled up.
+func (*tencentCloudImageDataDisk) FlatMapstructure() interface{ HCL2Spec() map[string]hcldec.Spec } {
+	return new(FlattencentCloudImageDataDisk)
+}
+
+// HCL2Spec returns the hcl spec of a tencentCloudImageDataDisk.
+// This spec is used by HCL to read the fields of tencentCloudImageDataDisk.
+// The decoded values from this spec will then be applied to a FlattencentCloudImageDataDisk.
+func (*FlattencentCloudImageDataDisk) HCL2Spec() map[string]hcldec.Spec {
+	s := map[string]hcldec.Spec{
+		"index":     &hcldec.AttrSpec{Name: "index", Type: cty.Number, Required: false},
+		"disk_size": &hcldec.AttrSpec{Name: "disk_size", Type: cty.Number, Required: false},
+		"mount_tag": &hcldec.AttrSpec{Name: "mount_tag", Type: cty.String, Required: false},
+	}
+	return s
+}
+
+// FlattencentCloudResourceFilter is an auto-generated flat version of tencentCloudResourceFilter.
+// Where the contents of a field with a `mapstructure:,squash` tag are bubbled up.
+type FlattencentCloudResourceFilter struct {
+	Name *string           `mapstructure:"name" cty:"name" hcl:"name"`
+	Tags map[string]string `mapstructure:"tags" cty:"tags" hcl:"tags"`
+}
+
+// FlatMapstructure returns a new FlattencentCloudResourceFilter.
+// FlattencentCloudResourceFilter is an auto-generated flat version of tencentCloudResourceFilter.
+// Where the contents a fields with a `mapstructure:,squash` tag are bubbled up.
+func (*tencentCloudResourceFilter) FlatMapstructure() interface{ HCL2Spec() map[string]hcldec.Spec } {
+	return new(FlattencentCloudResourceFilter)
+}
+
+// HCL2Spec returns the hcl spec of a tencentCloudResourceFilter.
+// This spec is used by HCL to read the fields of tencentCloudResourceFilter.
+// The decoded values from this spec will then be applied to a FlattencentCloudResourceFilter.
+func (*FlattencentCloudResourceFilter) HCL2Spec() map[string]hcldec.Spec {
+	s := map[string]hcldec.Spec{
+		"name": &hcldec.AttrSpec{Name: "name", Type: cty.String, Required: false},
+		"tags": &hcldec.AttrSpec{Name: "tags", Type: cty.Map(cty.String), Required: false},
+	}
+	return s
+}
+
+// FlattencentCloudSourceImageFilter is an auto-generated flat version of tencentCloudSourceImageFilter.
+// Where the contents of a field with a `mapstructure:,squash` tag are bubbled up.
+type FlattencentCloudSourceImageFilter struct {
+	ImageType *string           `mapstructure:"image_type" cty:"image_type" hcl:"image_type"`
+	Platform  *string           `mapstructure:"platform" cty:"platform" hcl:"platform"`
+	Tags      map[string]string `mapstructure:"tags" cty:"tags" hcl:"tags"`
+}
+
+// FlatMapstructure returns a new FlattencentCloudSourceImageFilter.
+// FlattencentCloudSourceImageFilter is an auto-generated flat version of tencentCloudSourceImageFilter.
+// Where the contents a fields with a `mapstructure:,squash` tag are bubbled up.
+func (*tencentCloudSourceImageFilter) FlatMapstructure() interface{ HCL2Spec() map[string]hcldec.Spec } {
+	return new(FlattencentCloudSourceImageFilter)
+}
+
+// HCL2Spec returns the hcl spec of a tencentCloudSourceImageFilter.
+// This spec is used by HCL to read the fields of tencentCloudSourceImageFilter.
+// The decoded values from this spec will then be applied to a FlattencentCloudSourceImageFilter.
+func (*FlattencentCloudSourceImageFilter) HCL2Spec() map[string]hcldec.Spec {
+	s := map[string]hcldec.Spec{
+		"image_type": &hcldec.AttrSpec{Name: "image_type", Type: cty.String, Required: false},
+		"platform":   &hcldec.AttrSpec{Name: "platform", Type: cty.String, Required: false},
+		"tags":       &hcldec.AttrSpec{Name: "tags", Type: cty.Map(cty.String), Required: false},
 	}
 	return s
 }
@@ -7,8 +7,11 @@ import (
 	"io/ioutil"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/hashicorp/packer-plugin-sdk/communicator"
+	"github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common"
+	cvm "github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/cvm/v20170312"
 )
 
 func testConfig() *TencentCloudRunConfig {
@@ -136,3 +139,419 @@ func TestTencentCloudRunConfigPrepare_SSHPrivateIp(t *testing.T) {
 		t.Fatalf("invalud ssh_private_ip value: %v", cf.SSHPrivateIp)
 	}
 }
+
+func TestTencentCloudRunConfigPrepare_SourceImageFilter(t *testing.T) {
+	cf := testConfig()
+	cf.SourceImageId = ""
+	cf.SourceImageFilter = tencentCloudSourceImageFilter{
+		ImageType: "PRIVATE_IMAGE",
+		Tags: map[string]string{
+			"role": "base",
+		},
+	}
+	if err := cf.Prepare(nil); err != nil {
+		t.Fatalf("shouldn't have error: %v", err)
+	}
+
+	if len(cf.SourceImageFilter.describeFilters()) != 2 {
+		t.Fatalf("invalid source image filters: %v", cf.SourceImageFilter.describeFilters())
+	}
+
+	cf.SourceImageId = "img-qwer1234"
+	if err := cf.Prepare(nil); err == nil {
+		t.Fatal("should have error: source_image_id conflicts with source_image_filter")
+	}
+
+	cf.SourceImageId = ""
+	cf.SourceImageFilter.ImageType = "UNKNOWN_IMAGE"
+	if err := cf.Prepare(nil); err == nil {
+		t.Fatal("should have error: invalid image_type")
+	}
+}
+
+func TestTencentCloudRunConfigPrepare_Spot(t *testing.T) {
+	cf := testConfig()
+	cf.SpotPrice = "0.5"
+	if err := cf.Prepare(nil); err != nil {
+		t.Fatalf("shouldn't have error: %v", err)
+	}
+
+	if cf.InstanceChargeType != "SPOTPAID" {
+		t.Fatalf("invalid instance_charge_type value: %v", cf.InstanceChargeType)
+	}
+
+	if cf.SpotInstanceType != "one-time" {
+		t.Fatalf("invalid spot_instance_type value: %v", cf.SpotInstanceType)
+	}
+
+	cf.SpotPrice = "cheap"
+	if err := cf.Prepare(nil); err == nil {
+		t.Fatal("should have error: invalid spot_price")
+	}
+
+	cf.SpotPrice = ""
+	cf.SpotInstanceType = "persistent"
+	if err := cf.Prepare(nil); err == nil {
+		t.Fatal("should have error: invalid spot_instance_type")
+	}
+
+	cf = testConfig()
+	cf.InstanceChargeType = "POSTPAID_BY_HOUR"
+	cf.SpotFallbackToPostpaid = true
+	if err := cf.Prepare(nil); err == nil {
+		t.Fatal("should have error: spot settings without SPOTPAID")
+	}
+}
+
+func TestTencentCloudRunConfigPrepare_InstanceTypes(t *testing.T) {
+	cf := testConfig()
+	cf.InstanceType = ""
+	cf.InstanceTypes = []string{"S5.MEDIUM2", "SA2.MEDIUM2", "S5.MEDIUM2"}
+	if err := cf.Prepare(nil); err != nil {
+		t.Fatalf("shouldn't have error: %v", err)
+	}
+
+	if cf.InstanceType != "S5.MEDIUM2" {
+		t.Fatalf("invalid instance_type value: %v", cf.InstanceType)
+	}
+
+	candidates := cf.CandidateInstanceTypes()
+	if len(candidates) != 2 || candidates[0] != "S5.MEDIUM2" || candidates[1] != "SA2.MEDIUM2" {
+		t.Fatalf("invalid candidate instance types: %v", candidates)
+	}
+}
+
+func TestTencentCloudRunConfigPrepare_Subnet(t *testing.T) {
+	cf := testConfig()
+	if err := cf.Prepare(nil); err != nil {
+		t.Fatalf("shouldn't have error: %v", err)
+	}
+	if cf.SubnectCidrBlock != "10.0.8.0/24" {
+		t.Fatalf("invalid subnet_cidr_block value: %v", cf.SubnectCidrBlock)
+	}
+
+	// Existing subnets of the vpc are reused.
+	cf = testConfig()
+	cf.VpcId = "vpc-qwer1234"
+	if err := cf.Prepare(nil); err != nil {
+		t.Fatalf("shouldn't have error: %v", err)
+	}
+	if cf.SubnectCidrBlock != "" {
+		t.Fatalf("subnet_cidr_block should be empty: %v", cf.SubnectCidrBlock)
+	}
+
+	cf = testConfig()
+	cf.CidrBlock = "10.0.0.0/16"
+	if err := cf.Prepare(nil); err == nil {
+		t.Fatal("should have error")
+	}
+}
+
+func TestTencentCloudRunConfigPrepare_NetworkFilters(t *testing.T) {
+	cf := testConfig()
+	cf.VpcFilter = tencentCloudResourceFilter{Name: "packer"}
+	cf.SubnetFilter = tencentCloudResourceFilter{Tags: map[string]string{"team": "network"}}
+	cf.SecurityGroupFilter = tencentCloudResourceFilter{Name: "packer"}
+	if err := cf.Prepare(nil); err != nil {
+		t.Fatalf("shouldn't have error: %v", err)
+	}
+	if cf.SubnectCidrBlock != "" || cf.SecurityGroupName != "" {
+		t.Fatalf("nothing should be created: %v, %v", cf.SubnectCidrBlock, cf.SecurityGroupName)
+	}
+
+	cf = testConfig()
+	cf.VpcId = "vpc-qwer1234"
+	cf.VpcFilter = tencentCloudResourceFilter{Name: "packer"}
+	if err := cf.Prepare(nil); err == nil {
+		t.Fatal("should have error")
+	}
+
+	cf = testConfig()
+	cf.SubnetFilter = tencentCloudResourceFilter{Name: "packer"}
+	if err := cf.Prepare(nil); err == nil {
+		t.Fatal("should have error")
+	}
+
+	cf = testConfig()
+	cf.SecurityGroupId = "sg-qwer1234"
+	cf.SecurityGroupFilter = tencentCloudResourceFilter{Name: "packer"}
+	if err := cf.Prepare(nil); err == nil {
+		t.Fatal("should have error")
+	}
+}
+
+func TestTencentCloudResourceFilter(t *testing.T) {
+	f := tencentCloudResourceFilter{
+		Name: "packer",
+		Tags: map[string]string{"team": "network"},
+	}
+
+	filters := f.describeFilters("vpc-name")
+	if len(filters) != 2 || *filters[0].Name != "vpc-name" || *filters[0].Values[0] != "packer" ||
+		*filters[1].Name != "tag:team" || *filters[1].Values[0] != "network" {
+		t.Fatalf("invalid filters: %v", filters)
+	}
+
+	name := "packer-test"
+	if f.matchName(&name) || f.matchName(nil) {
+		t.Fatal("name shouldn't match")
+	}
+	name = "packer"
+	if !f.matchName(&name) {
+		t.Fatal("name should match")
+	}
+}
+
+func TestTencentCloudRunConfigPrepare_TemporarySecurityGroup(t *testing.T) {
+	cf := testConfig()
+	if err := cf.Prepare(nil); err != nil {
+		t.Fatalf("shouldn't have error: %v", err)
+	}
+	if len(cf.TemporarySecurityGroupSourceCidrs) != 1 || cf.TemporarySecurityGroupSourceCidrs[0] != "0.0.0.0/0" {
+		t.Fatalf("invalid temporary_security_group_source_cidrs: %v", cf.TemporarySecurityGroupSourceCidrs)
+	}
+	if len(cf.TemporarySecurityGroupEgressCidrs) != 1 || cf.TemporarySecurityGroupEgressCidrs[0] != "0.0.0.0/0" {
+		t.Fatalf("invalid temporary_security_group_egress_cidrs: %v", cf.TemporarySecurityGroupEgressCidrs)
+	}
+
+	cf = testConfig()
+	cf.TemporarySecurityGroupSourcePublicIp = true
+	if err := cf.Prepare(nil); err != nil {
+		t.Fatalf("shouldn't have error: %v", err)
+	}
+	if len(cf.TemporarySecurityGroupSourceCidrs) != 0 {
+		t.Fatalf("temporary_security_group_source_cidrs should be empty: %v", cf.TemporarySecurityGroupSourceCidrs)
+	}
+
+	cf = testConfig()
+	cf.TemporarySecurityGroupSourceCidrs = []string{"10.0.0.0/8"}
+	cf.TemporarySecurityGroupSourcePublicIp = true
+	if err := cf.Prepare(nil); err == nil {
+		t.Fatal("should have error")
+	}
+
+	cf = testConfig()
+	cf.TemporarySecurityGroupSourceCidrs = []string{"10.0.0.0"}
+	if err := cf.Prepare(nil); err == nil {
+		t.Fatal("should have error")
+	}
+
+	cf = testConfig()
+	cf.SecurityGroupId = "sg-qwer1234"
+	cf.TemporarySecurityGroupEgressCidrs = []string{"10.0.0.0/8"}
+	if err := cf.Prepare(nil); err == nil {
+		t.Fatal("should have error")
+	}
+}
+
+func TestTencentCloudRunConfigPrepare_SecurityGroupIds(t *testing.T) {
+	cf := testConfig()
+	cf.SecurityGroupIds = []string{"sg-qwer1234", "sg-asdf5678"}
+	cf.TemporarySecurityGroupSourcePublicIp = true
+	if err := cf.Prepare(nil); err != nil {
+		t.Fatalf("shouldn't have error: %v", err)
+	}
+	if cf.SecurityGroupName == "" {
+		t.Fatal("temporary securitygroup should still be created")
+	}
+
+	cf = testConfig()
+	cf.SecurityGroupIds = []string{"qwer1234"}
+	if err := cf.Prepare(nil); err == nil {
+		t.Fatal("should have error")
+	}
+}
+
+func TestTencentCloudRunConfigPrepare_TatCommunicator(t *testing.T) {
+	cf := testConfig()
+	cf.Comm = communicator.Config{Type: "tat"}
+	if err := cf.Prepare(nil); err != nil {
+		t.Fatalf("shouldn't have error: %v", err)
+	}
+	if cf.Comm.Type != "tat" {
+		t.Fatalf("invalid communicator type: %v", cf.Comm.Type)
+	}
+	if cf.Comm.SSHTemporaryKeyPairName != "" {
+		t.Fatalf("temporary key pair shouldn't be created: %v", cf.Comm.SSHTemporaryKeyPairName)
+	}
+	if cf.TatCommandTimeout != time.Hour || cf.TatAgentTimeout != 10*time.Minute {
+		t.Fatalf("invalid tat timeouts: %v, %v", cf.TatCommandTimeout, cf.TatAgentTimeout)
+	}
+
+	cf = testConfig()
+	cf.Comm = communicator.Config{Type: "tat"}
+	cf.TatCommandTimeout = 48 * time.Hour
+	if err := cf.Prepare(nil); err == nil {
+		t.Fatal("should have error")
+	}
+
+	cf = testConfig()
+	cf.TatUsername = "packer"
+	if err := cf.Prepare(nil); err == nil {
+		t.Fatal("should have error")
+	}
+}
+
+func TestTencentCloudRunConfig_DiskEncryption(t *testing.T) {
+	cf := testConfig()
+	cf.DiskEncrypt = true
+	cf.DataDisks = []tencentCloudDataDisk{
+		{DiskType: "CLOUD_SSD", DiskSize: 100, Encrypt: true, KmsKeyId: "kms-key"},
+	}
+	if err := cf.Prepare(nil); err != nil {
+		t.Fatalf("shouldn't have err: %v", err)
+	}
+
+	cf.DataDisks[0].Encrypt = false
+	if err := cf.Prepare(nil); err == nil {
+		t.Fatal("should have err: kms_key_id without encrypt")
+	}
+
+	cf.DataDisks[0] = tencentCloudDataDisk{DiskType: "LOCAL_SSD", DiskSize: 100, Encrypt: true}
+	if err := cf.Prepare(nil); err == nil {
+		t.Fatal("should have err: local disk can't be encrypted")
+	}
+
+	cf.DataDisks = nil
+	cf.DiskType = "LOCAL_BASIC"
+	if err := cf.Prepare(nil); err == nil {
+		t.Fatal("should have err: local root disk can't be encrypted")
+	}
+}
+
+func TestTencentCloudRunConfig_DataDiskOverride(t *testing.T) {
+	cf := testConfig()
+	index := 0
+	cf.DataDisks = []tencentCloudDataDisk{{SourceDiskIndex: &index, DiskType: "CLOUD_SSD"}}
+	if err := cf.Prepare(nil); err != nil {
+		t.Fatalf("shouldn't have err: %v", err)
+	}
+
+	cf.DataDisks[0].SnapshotId = "snap-qwer1234"
+	if err := cf.Prepare(nil); err == nil {
+		t.Fatal("should have err: source_disk_index conflicts with disk_snapshot_id")
+	}
+
+	index = -1
+	cf.DataDisks[0].SnapshotId = ""
+	if err := cf.Prepare(nil); err == nil {
+		t.Fatal("should have err: negative source_disk_index")
+	}
+}
+
+func TestTencentCloudRunConfig_ImageDataDisks(t *testing.T) {
+	cf := testConfig()
+	cf.ImageDataDisks = []tencentCloudImageDataDisk{{MountTag: "data"}, {DiskSize: 100}}
+	if err := cf.Prepare(nil); err != nil {
+		t.Fatalf("shouldn't have err: %v", err)
+	}
+
+	cf.ImageSystemDiskOnly = true
+	if err := cf.Prepare(nil); err == nil {
+		t.Fatal("should have err: image_system_disk_only conflicts with image_data_disks")
+	}
+
+	cf.ImageSystemDiskOnly = false
+	cf.ImageDataDisks = []tencentCloudImageDataDisk{{MountTag: "data", DiskSize: 100}}
+	if err := cf.Prepare(nil); err == nil {
+		t.Fatal("should have err: more than one selector")
+	}
+}
+
+func TestTencentCloudRunConfig_DiskSize(t *testing.T) {
+	cf := testConfig()
+	cf.DiskType = "CLOUD_BASIC"
+	cf.DiskSize = 600
+	if err := cf.Prepare(nil); err == nil {
+		t.Fatal("should have err: disk_size exceeds CLOUD_BASIC maximum")
+	}
+
+	cf.DiskSize = 500
+	if err := cf.Prepare(nil); err != nil {
+		t.Fatalf("shouldn't have err: %v", err)
+	}
+
+	cf.DiskSize = 0
+	if err := cf.Prepare(nil); err != nil {
+		t.Fatalf("shouldn't have err: %v", err)
+	}
+	if cf.DiskSize != 0 {
+		t.Fatalf("unset disk_size should be left to the source image, got %d", cf.DiskSize)
+	}
+}
+
+func TestCheckSystemDiskSize(t *testing.T) {
+	image := &cvm.Image{
+		ImageId:   common.StringPtr("img-qwer1234"),
+		ImageSize: common.Int64Ptr(50),
+		SnapshotSet: []*cvm.Snapshot{
+			{DiskUsage: common.StringPtr("SYSTEM_DISK"), DiskSize: common.Int64Ptr(80)},
+			{DiskUsage: common.StringPtr("DATA_DISK"), DiskSize: common.Int64Ptr(200)},
+		},
+	}
+
+	if size, err := checkSystemDiskSize(image, "CLOUD_PREMIUM", 100, false); err != nil || size != 100 {
+		t.Fatalf("unexpected size %d: %v", size, err)
+	}
+	if _, err := checkSystemDiskSize(image, "CLOUD_PREMIUM", 50, false); err == nil {
+		t.Fatal("should have err: disk_size smaller than image system disk")
+	}
+	if size, err := checkSystemDiskSize(image, "CLOUD_PREMIUM", 50, true); err != nil || size != 80 {
+		t.Fatalf("unexpected size %d: %v", size, err)
+	}
+	if _, err := checkSystemDiskSize(image, "LOCAL_BASIC", 50, true); err == nil {
+		t.Fatal("should have err: image system disk exceeds LOCAL_BASIC maximum")
+	}
+	if size, err := checkSystemDiskSize(image, "CLOUD_PREMIUM", 0, false); err != nil || size != 80 {
+		t.Fatalf("unset disk_size should grow to the image system disk, got %d: %v", size, err)
+	}
+	image.SnapshotSet[0].DiskSize = common.Int64Ptr(20)
+	if size, err := checkSystemDiskSize(image, "CLOUD_PREMIUM", 0, false); err != nil || size != 50 {
+		t.Fatalf("unset disk_size should default to 50, got %d: %v", size, err)
+	}
+}
+
+func TestTencentCloudRunConfig_DataDiskPerformance(t *testing.T) {
+	cf := testConfig()
+	cf.DataDisks = []tencentCloudDataDisk{{DiskType: "CLOUD_HSSD", DiskSize: 100, ThroughputPerformance: 100}}
+	if err := cf.Prepare(nil); err != nil {
+		t.Fatalf("shouldn't have err: %v", err)
+	}
+
+	cf.DataDisks[0].DiskType = "CLOUD_SSD"
+	if err := cf.Prepare(nil); err == nil {
+		t.Fatal("should have err: throughput_performance unsupported by CLOUD_SSD")
+	}
+
+	cf.DataDisks[0].DiskType = "CLOUD_UNKNOWN"
+	cf.DataDisks[0].ThroughputPerformance = 0
+	if err := cf.Prepare(nil); err == nil {
+		t.Fatal("should have err: invalid data disk type")
+	}
+}
+
+func TestTencentCloudRunConfig_DataDiskDeleteWithInstance(t *testing.T) {
+	cf := testConfig()
+	cf.DataDisks = []tencentCloudDataDisk{
+		{DiskType: "CLOUD_SSD", DiskSize: 100, DeleteWithInstance: common.BoolPtr(false), MountTag: "data"},
+	}
+	if err := cf.Prepare(nil); err != nil {
+		t.Fatalf("shouldn't have err: %v", err)
+	}
+
+	cf.ImageDataDisks = []tencentCloudImageDataDisk{{MountTag: "data"}}
+	if err := cf.Prepare(nil); err != nil {
+		t.Fatalf("shouldn't have err: %v", err)
+	}
+
+	cf.ImageDataDisks = []tencentCloudImageDataDisk{{MountTag: "cache"}}
+	if err := cf.Prepare(nil); err == nil {
+		t.Fatal("should have err: disk kept after the build is not captured")
+	}
+
+	cf.ImageDataDisks = nil
+	cf.ImageSystemDiskOnly = true
+	if err := cf.Prepare(nil); err == nil {
+		t.Fatal("should have err: disk kept after the build is not captured")
+	}
+}
@@ -9,11 +9,12 @@ import (
 	"regexp"
 
 	"github.com/hashicorp/packer-plugin-sdk/multistep"
+	"github.com/hashicorp/packer-plugin-sdk/packerbuilderdata"
 	cvm "github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/cvm/v20170312"
 )
 
 type stepCheckSourceImage struct {
-	sourceImageId string
+	GeneratedData *packerbuilderdata.GeneratedData
 }
 
 func (s *stepCheckSourceImage) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
@@ -31,39 +32,84 @@ func (s *stepCheckSourceImage) Run(ctx context.Context, state multistep.StateBag
 	if config.SourceImageId != "" {
 		req.ImageIds = []*string{&config.SourceImageId}
 	} else {
-		imageNameRegex, err = regexp.Compile(config.SourceImageName)
-		if err != nil {
-			return Halt(state, fmt.Errorf("regex compilation error"), "Bad input")
+		if filters := config.SourceImageFilter.describeFilters(); len(filters) > 0 {
+			req.Filters = filters
+		}
+		if config.SourceImageName != "" {
+			imageNameRegex, err = regexp.Compile(config.SourceImageName)
+			if err != nil {
+				return Halt(state, fmt.Errorf("regex compilation error"), "Bad input")
+			}
 		}
 	}
-	var resp *cvm.DescribeImagesResponse
-	err = Retry(ctx, func(ctx context.Context) error {
-		var err error
-		resp, err = client.DescribeImages(req)
-		return err
-	})
+
+	images, err := GetImages(ctx, client, req)
 	if err != nil {
 		return Halt(state, err, "Failed to get source image info")
 	}
 
-	if *resp.Response.TotalCount > 0 {
-		images := resp.Response.ImageSet
-		if imageNameRegex != nil {
-			for _, image := range images {
-				if imageNameRegex.MatchString(*image.ImageName) {
-					state.Put("source_image", image)
-					Message(state, *image.ImageName, "Image found")
-					return multistep.ActionContinue
-				}
+	if imageNameRegex != nil {
+		var matched []*cvm.Image
+		for _, image := range images {
+			if imageNameRegex.MatchString(*image.ImageName) {
+				matched = append(matched, image)
 			}
-		} else {
-			state.Put("source_image", images[0])
-			Message(state, *resp.Response.ImageSet[0].ImageName, "Image found")
-			return multistep.ActionContinue
 		}
+		images = matched
+	}
+
+	if len(images) == 0 {
+		return Halt(state, fmt.Errorf("No image found under current instance_type(%s) restriction", config.InstanceType), "")
+	}
+
+	if len(images) > 1 && !config.SourceImageMostRecent {
+		return Halt(state, fmt.Errorf("%d images found under current source image restriction, "+
+			"please use a more specific one or set source_image_most_recent", len(images)), "")
+	}
+
+	image := MostRecentImage(images)
+	state.Put("source_image", image)
+	Message(state, *image.ImageName, "Image found")
+
+	if config.DiskEncrypt {
+		Say(state, *image.ImageId, "Trying to check system disk snapshot of source image is encrypted")
+		cbsClient := state.Get("cbs_client").(*CbsClient)
+		if err := checkSystemDiskEncrypted(ctx, cbsClient, image); err != nil {
+			return Halt(state, err, "Failed to check system disk snapshot")
+		}
+	}
+
+	s.GeneratedData.Put("SourceImageId", *image.ImageId)
+	s.GeneratedData.Put("SourceImageName", *image.ImageName)
+	if image.Platform != nil {
+		s.GeneratedData.Put("SourceImagePlatform", *image.Platform)
+	}
+
+	return multistep.ActionContinue
+}
+
+// checkSystemDiskEncrypted checks the system disk snapshot of the image is
+// encrypted, RunInstances having no way to encrypt a root disk created from
+// an unencrypted one.
+func checkSystemDiskEncrypted(ctx context.Context, client *CbsClient, image *cvm.Image) error {
+	for _, snapshot := range image.SnapshotSet {
+		if snapshot.DiskUsage == nil || *snapshot.DiskUsage != "SYSTEM_DISK" {
+			continue
+		}
+
+		cbsSnapshot, err := client.DescribeSnapshot(ctx, *snapshot.SnapshotId)
+		if err != nil {
+			return err
+		}
+		if cbsSnapshot.Encrypt == nil || !*cbsSnapshot.Encrypt {
+			return fmt.Errorf("The system disk snapshot(%s) of source image(%s) is not encrypted, "+
+				"so the root disk can't be encrypted", *snapshot.SnapshotId, *image.ImageId)
+		}
+		return nil
 	}
 
-	return Halt(state, fmt.Errorf("No image found under current instance_type(%s) restriction", config.InstanceType), "")
+	return fmt.Errorf("The source image(%s) has no system disk snapshot, "+
+		"so the root disk can't be encrypted", *image.ImageId)
 }
 
 func (s *stepCheckSourceImage) Cleanup(bag multistep.StateBag) {}
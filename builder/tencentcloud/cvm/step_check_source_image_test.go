@@ -0,0 +1,152 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package cvm
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/packer-plugin-sdk/multistep"
+	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
+	"github.com/hashicorp/packer-plugin-sdk/packerbuilderdata"
+	cvm "github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/cvm/v20170312"
+)
+
+func TestStepCheckSourceImage(t *testing.T) {
+	// 150 images over two pages, the centos ones on both pages with two of
+	// them created at the same time.
+	var images []map[string]string
+	for i := 0; i < 150; i++ {
+		name, createdTime := fmt.Sprintf("other-%d", i), "2024-01-01T00:00:00Z"
+		switch i {
+		case 10, 120:
+			name, createdTime = fmt.Sprintf("centos-%d", i), "2024-02-01T00:00:00Z"
+		case 130:
+			name, createdTime = "centos-130", "2024-01-15T00:00:00Z"
+		}
+		images = append(images, map[string]string{
+			"ImageId":     fmt.Sprintf("img-%08d", i),
+			"ImageName":   name,
+			"CreatedTime": createdTime,
+		})
+	}
+
+	pages := 0
+	_, accessConfig := newFakeCvm(t, map[string]fakeCvmHandler{
+		"DescribeImages": func(call fakeCvmCall) interface{} {
+			pages++
+			var req struct{ Offset, Limit int }
+			call.decode(&req)
+			end := req.Offset + req.Limit
+			if end > len(images) {
+				end = len(images)
+			}
+			return map[string]interface{}{"ImageSet": images[req.Offset:end], "TotalCount": len(images)}
+		},
+	})
+	client, err := NewCvmClient(accessConfig)
+	if err != nil {
+		t.Fatalf("shouldn't have err: %v", err)
+	}
+
+	run := func(mostRecent bool) (multistep.StepAction, multistep.StateBag) {
+		config := &Config{}
+		config.InstanceType = "S5.MEDIUM2"
+		config.SourceImageName = "^centos-"
+		config.SourceImageMostRecent = mostRecent
+
+		state := new(multistep.BasicStateBag)
+		state.Put("ui", packersdk.TestUi(t))
+		state.Put("config", config)
+		state.Put("cvm_client", client)
+		step := &stepCheckSourceImage{GeneratedData: &packerbuilderdata.GeneratedData{State: state}}
+
+		return step.Run(context.Background(), state), state
+	}
+
+	if action, _ := run(false); action != multistep.ActionHalt {
+		t.Fatal("should halt on multiple matching images")
+	}
+	if pages != 2 {
+		t.Fatalf("every page should be read, got %d", pages)
+	}
+
+	action, state := run(true)
+	if action != multistep.ActionContinue {
+		t.Fatalf("shouldn't halt: %v", state.Get("error"))
+	}
+	if id := *state.Get("source_image").(*cvm.Image).ImageId; id != "img-00000120" {
+		t.Fatalf("most recent image should be img-00000120, got %s", id)
+	}
+	if id := state.Get("generated_data").(map[string]interface{})["SourceImageId"]; id != "img-00000120" {
+		t.Fatalf("SourceImageId should be generated, got %v", id)
+	}
+}
+
+func TestStepCheckSourceImage_DiskEncrypt(t *testing.T) {
+	encrypted := map[string]bool{"snap-encrypted": true, "snap-plain": false}
+	var systemSnapshot string
+	_, accessConfig := newFakeCvm(t, map[string]fakeCvmHandler{
+		"DescribeImages": func(call fakeCvmCall) interface{} {
+			image := map[string]interface{}{
+				"ImageId":   "img-00000001",
+				"ImageName": "centos",
+				"SnapshotSet": []map[string]string{
+					{"SnapshotId": "snap-data", "DiskUsage": "DATA_DISK"},
+				},
+			}
+			if systemSnapshot != "" {
+				image["SnapshotSet"] = []map[string]string{
+					{"SnapshotId": "snap-data", "DiskUsage": "DATA_DISK"},
+					{"SnapshotId": systemSnapshot, "DiskUsage": "SYSTEM_DISK"},
+				}
+			}
+			return map[string]interface{}{"ImageSet": []interface{}{image}, "TotalCount": 1}
+		},
+		"DescribeSnapshots": func(call fakeCvmCall) interface{} {
+			var req struct{ SnapshotIds []string }
+			call.decode(&req)
+			return map[string]interface{}{"SnapshotSet": []map[string]interface{}{
+				{"SnapshotId": req.SnapshotIds[0], "Encrypt": encrypted[req.SnapshotIds[0]]},
+			}}
+		},
+	})
+	cvmClient, err := NewCvmClient(accessConfig)
+	if err != nil {
+		t.Fatalf("shouldn't have err: %v", err)
+	}
+	cbsClient, err := NewCbsClient(accessConfig)
+	if err != nil {
+		t.Fatalf("shouldn't have err: %v", err)
+	}
+
+	run := func() multistep.StepAction {
+		config := &Config{}
+		config.InstanceType = "S5.MEDIUM2"
+		config.SourceImageId = "img-00000001"
+		config.DiskEncrypt = true
+
+		state := new(multistep.BasicStateBag)
+		state.Put("ui", packersdk.TestUi(t))
+		state.Put("config", config)
+		state.Put("cvm_client", cvmClient)
+		state.Put("cbs_client", cbsClient)
+		step := &stepCheckSourceImage{GeneratedData: &packerbuilderdata.GeneratedData{State: state}}
+
+		return step.Run(context.Background(), state)
+	}
+
+	if action := run(); action != multistep.ActionHalt {
+		t.Fatal("should halt without system disk snapshot")
+	}
+	systemSnapshot = "snap-plain"
+	if action := run(); action != multistep.ActionHalt {
+		t.Fatal("should halt on unencrypted system disk snapshot")
+	}
+	systemSnapshot = "snap-encrypted"
+	if action := run(); action != multistep.ActionContinue {
+		t.Fatal("shouldn't halt on encrypted system disk snapshot")
+	}
+}
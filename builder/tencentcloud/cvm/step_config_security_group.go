@@ -6,21 +6,68 @@ package cvm
 import (
 	"context"
 	"fmt"
+	"strconv"
+	"strings"
 
 	"github.com/hashicorp/packer-plugin-sdk/multistep"
+	"github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common"
 	vpc "github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/vpc/v20170312"
 )
 
 type stepConfigSecurityGroup struct {
-	SecurityGroupId   string
-	SecurityGroupName string
-	Description       string
-	isCreate          bool
+	SecurityGroupId     string
+	SecurityGroupFilter tencentCloudResourceFilter
+	SecurityGroupName   string
+	Description         string
+	// Communicator port opened to the source cidr blocks, 0 for none
+	Port           int
+	SourceCidrs    []string
+	SourcePublicIp bool
+	EgressCidrs    []string
+	// Additional existing securitygroups attached to the instance
+	SecurityGroupIds []string
+	isCreate         bool
 }
 
 func (s *stepConfigSecurityGroup) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
 	vpcClient := state.Get("vpc_client").(*vpc.Client)
 
+	if len(s.SecurityGroupIds) > 0 {
+		Say(state, strings.Join(s.SecurityGroupIds, ","), "Trying to use additional securitygroups")
+		req := vpc.NewDescribeSecurityGroupsRequest()
+		req.SecurityGroupIds = common.StringPtrs(s.SecurityGroupIds)
+		groups, err := GetSecurityGroups(ctx, vpcClient, req)
+		if err != nil {
+			return Halt(state, err, "Failed to get securitygroup info")
+		}
+		found := make(map[string]struct{}, len(groups))
+		for _, group := range groups {
+			found[*group.SecurityGroupId] = struct{}{}
+		}
+		for _, id := range s.SecurityGroupIds {
+			if _, ok := found[id]; !ok {
+				return Halt(state, fmt.Errorf("The specified securitygroup(%s) does not exists", id), "")
+			}
+		}
+		Message(state, strings.Join(s.SecurityGroupIds, ","), "Securitygroups found")
+	}
+
+	action := s.configSecurityGroup(ctx, state)
+	if action != multistep.ActionContinue {
+		return action
+	}
+
+	securityGroupId := state.Get("security_group_id").(string)
+	state.Put("security_group_ids", uniqueStrings(append([]string{securityGroupId}, s.SecurityGroupIds...)))
+
+	return multistep.ActionContinue
+}
+
+// configSecurityGroup resolves the securitygroup Packer manages the rules
+// of, creating a temporary one if no existing securitygroup is specified.
+func (s *stepConfigSecurityGroup) configSecurityGroup(ctx context.Context, state multistep.StateBag) multistep.StepAction {
+	vpcClient := state.Get("vpc_client").(*vpc.Client)
+
 	if len(s.SecurityGroupId) != 0 {
 		Say(state, s.SecurityGroupId, "Trying to use existing securitygroup")
 		req := vpc.NewDescribeSecurityGroupsRequest()
@@ -43,6 +90,34 @@ func (s *stepConfigSecurityGroup) Run(ctx context.Context, state multistep.State
 		return Halt(state, fmt.Errorf("The specified securitygroup(%s) does not exists", s.SecurityGroupId), "")
 	}
 
+	if !s.SecurityGroupFilter.Empty() {
+		Say(state, "Trying to find existing securitygroup by security_group_filter", "")
+		req := vpc.NewDescribeSecurityGroupsRequest()
+		req.Filters = s.SecurityGroupFilter.describeFilters("security-group-name")
+		groups, err := GetSecurityGroups(ctx, vpcClient, req)
+		if err != nil {
+			return Halt(state, err, "Failed to get securitygroup info")
+		}
+		var matched []*vpc.SecurityGroup
+		for _, group := range groups {
+			if s.SecurityGroupFilter.matchName(group.SecurityGroupName) {
+				matched = append(matched, group)
+			}
+		}
+		if len(matched) == 0 {
+			return Halt(state, fmt.Errorf("No securitygroup found using the specified security_group_filter"), "")
+		}
+		if len(matched) > 1 {
+			return Halt(state, fmt.Errorf("The specified security_group_filter matches %d securitygroups, "+
+				"please try a more specific filter", len(matched)), "")
+		}
+		s.isCreate = false
+		state.Put("security_group_id", *matched[0].SecurityGroupId)
+		Message(state, fmt.Sprintf("%s(%s)", *matched[0].SecurityGroupName, *matched[0].SecurityGroupId),
+			"Securitygroup found")
+		return multistep.ActionContinue
+	}
+
 	Say(state, "Trying to create a new securitygroup", "")
 
 	req := vpc.NewCreateSecurityGroupRequest()
@@ -65,36 +140,48 @@ func (s *stepConfigSecurityGroup) Run(ctx context.Context, state multistep.State
 
 	// bind securitygroup ingress police
 	Say(state, "Trying to create securitygroup polices", "")
-	pReq := vpc.NewCreateSecurityGroupPoliciesRequest()
 	ACCEPT := "ACCEPT"
-	DEFAULT_CIDR := "0.0.0.0/0"
-	pReq.SecurityGroupId = &s.SecurityGroupId
-	pReq.SecurityGroupPolicySet = &vpc.SecurityGroupPolicySet{
-		Ingress: []*vpc.SecurityGroupPolicy{
-			{
-				CidrBlock: &DEFAULT_CIDR,
-				Action:    &ACCEPT,
-			},
-		},
+	TCP := "TCP"
+	port := strconv.Itoa(s.Port)
+	sourceCidrs := s.SourceCidrs
+	if s.SourcePublicIp {
+		ip, err := GetPublicIp(ctx)
+		if err != nil {
+			return Halt(state, err, "Failed to detect public ip")
+		}
+		Message(state, ip, "Public ip detected")
+		sourceCidrs = []string{ip + "/32"}
 	}
-	err = Retry(ctx, func(ctx context.Context) error {
-		_, e := vpcClient.CreateSecurityGroupPolicies(pReq)
-		return e
-	})
-	if err != nil {
-		return Halt(state, err, "Failed to create securitygroup polices")
+	if s.Port > 0 && len(sourceCidrs) > 0 {
+		pReq := vpc.NewCreateSecurityGroupPoliciesRequest()
+		pReq.SecurityGroupId = &s.SecurityGroupId
+		pReq.SecurityGroupPolicySet = &vpc.SecurityGroupPolicySet{}
+		for i := range sourceCidrs {
+			pReq.SecurityGroupPolicySet.Ingress = append(pReq.SecurityGroupPolicySet.Ingress, &vpc.SecurityGroupPolicy{
+				Protocol:  &TCP,
+				Port:      &port,
+				CidrBlock: &sourceCidrs[i],
+				Action:    &ACCEPT,
+			})
+		}
+		err = Retry(ctx, func(ctx context.Context) error {
+			_, e := vpcClient.CreateSecurityGroupPolicies(pReq)
+			return e
+		})
+		if err != nil {
+			return Halt(state, err, "Failed to create securitygroup polices")
+		}
 	}
 
 	// bind securitygroup engress police
-	pReq = vpc.NewCreateSecurityGroupPoliciesRequest()
+	pReq := vpc.NewCreateSecurityGroupPoliciesRequest()
 	pReq.SecurityGroupId = &s.SecurityGroupId
-	pReq.SecurityGroupPolicySet = &vpc.SecurityGroupPolicySet{
-		Egress: []*vpc.SecurityGroupPolicy{
-			{
-				CidrBlock: &DEFAULT_CIDR,
-				Action:    &ACCEPT,
-			},
-		},
+	pReq.SecurityGroupPolicySet = &vpc.SecurityGroupPolicySet{}
+	for i := range s.EgressCidrs {
+		pReq.SecurityGroupPolicySet.Egress = append(pReq.SecurityGroupPolicySet.Egress, &vpc.SecurityGroupPolicy{
+			CidrBlock: &s.EgressCidrs[i],
+			Action:    &ACCEPT,
+		})
 	}
 	err = Retry(ctx, func(ctx context.Context) error {
 		_, e := vpcClient.CreateSecurityGroupPolicies(pReq)
@@ -6,23 +6,26 @@ package cvm
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/hashicorp/packer-plugin-sdk/multistep"
+	"github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common"
 	vpc "github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/vpc/v20170312"
 )
 
 type stepConfigSubnet struct {
 	SubnetId        string
+	SubnetFilter    tencentCloudResourceFilter
 	SubnetCidrBlock string
 	SubnetName      string
-	Zone            string
-	isCreate        bool
+	createdSubnets  []string
 }
 
 func (s *stepConfigSubnet) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
 	vpcClient := state.Get("vpc_client").(*vpc.Client)
 
 	vpcId := state.Get("vpc_id").(string)
+	zones := state.Get("zones").([]string)
 
 	if len(s.SubnetId) != 0 {
 		Say(state, s.SubnetId, "Trying to use existing subnet")
@@ -38,25 +41,66 @@ func (s *stepConfigSubnet) Run(ctx context.Context, state multistep.StateBag) mu
 			return Halt(state, err, "Failed to get subnet info")
 		}
 		if *resp.Response.TotalCount > 0 {
-			s.isCreate = false
-			if *resp.Response.SubnetSet[0].VpcId != vpcId {
+			subnet := resp.Response.SubnetSet[0]
+			if *subnet.VpcId != vpcId {
 				return Halt(state, fmt.Errorf("The specified subnet(%s) does not belong to the specified vpc(%s)",
 					s.SubnetId, vpcId), "")
 			}
-			state.Put("subnet_id", *resp.Response.SubnetSet[0].SubnetId)
-			Message(state, *resp.Response.SubnetSet[0].SubnetName, "Subnet found")
+			if err := useSubnet(state, subnet, zones); err != nil {
+				return Halt(state, err, "")
+			}
+			Message(state, *subnet.SubnetName, "Subnet found")
 			return multistep.ActionContinue
 		}
 		return Halt(state, fmt.Errorf("The specified subnet(%s) does not exist", s.SubnetId), "")
 	}
 
-	Say(state, "Trying to create a new subnet", "")
+	if !s.SubnetFilter.Empty() {
+		Say(state, "Trying to find existing subnet by subnet_filter", "")
+		req := vpc.NewDescribeSubnetsRequest()
+		req.Filters = append(s.SubnetFilter.describeFilters("subnet-name"), &vpc.Filter{
+			Name:   common.StringPtr("vpc-id"),
+			Values: []*string{&vpcId},
+		})
+		subnets, err := GetSubnets(ctx, vpcClient, req)
+		if err != nil {
+			return Halt(state, err, "Failed to get subnet info")
+		}
+		var matched []*vpc.Subnet
+		for _, subnet := range subnets {
+			if s.SubnetFilter.matchName(subnet.SubnetName) {
+				matched = append(matched, subnet)
+			}
+		}
+		if len(matched) == 0 {
+			return Halt(state, fmt.Errorf("No subnet found in vpc(%s) using the specified subnet_filter", vpcId), "")
+		}
+		if len(matched) > 1 {
+			return Halt(state, fmt.Errorf("The specified subnet_filter matches %d subnets, "+
+				"please try a more specific filter", len(matched)), "")
+		}
+		subnet := matched[0]
+		if err := useSubnet(state, subnet, zones); err != nil {
+			return Halt(state, err, "")
+		}
+		Message(state, fmt.Sprintf("%s(%s)", *subnet.SubnetName, *subnet.SubnetId), "Subnet found")
+		return multistep.ActionContinue
+	}
+
+	if len(s.SubnetCidrBlock) == 0 {
+		return s.reuseSubnets(ctx, state, vpcId, zones)
+	}
+
+	// Only the first zone offering the instance types gets a subnet, the
+	// instance is launched there.
+	zone := zones[0]
+	Say(state, zone, "Trying to create a new subnet in zone")
 
 	req := vpc.NewCreateSubnetRequest()
 	req.VpcId = &vpcId
 	req.SubnetName = &s.SubnetName
 	req.CidrBlock = &s.SubnetCidrBlock
-	req.Zone = &s.Zone
+	req.Zone = &zone
 	var resp *vpc.CreateSubnetResponse
 	err := Retry(ctx, func(ctx context.Context) error {
 		var e error
@@ -67,16 +111,83 @@ func (s *stepConfigSubnet) Run(ctx context.Context, state multistep.StateBag) mu
 		return Halt(state, err, "Failed to create subnet")
 	}
 
-	s.isCreate = true
-	s.SubnetId = *resp.Response.Subnet.SubnetId
-	state.Put("subnet_id", s.SubnetId)
-	Message(state, s.SubnetId, "Subnet created")
+	subnetId := *resp.Response.Subnet.SubnetId
+	s.createdSubnets = append(s.createdSubnets, subnetId)
+	state.Put("subnet_id", subnetId)
+	state.Put("subnet_ids", map[string]string{zone: subnetId})
+	state.Put("zones", []string{zone})
+	Message(state, subnetId, "Subnet created")
+
+	return multistep.ActionContinue
+}
+
+// useSubnet launches the instance in the given subnet, whose zone must be
+// one of the zones offering the instance types.
+func useSubnet(state multistep.StateBag, subnet *vpc.Subnet, zones []string) error {
+	if !containsString(zones, *subnet.Zone) {
+		return fmt.Errorf("The zone(%s) of the specified subnet(%s) does not offer the instance types",
+			*subnet.Zone, *subnet.SubnetId)
+	}
+
+	state.Put("subnet_id", *subnet.SubnetId)
+	state.Put("subnet_ids", map[string]string{*subnet.Zone: *subnet.SubnetId})
+	state.Put("zones", []string{*subnet.Zone})
+
+	return nil
+}
+
+// reuseSubnets looks for the existing subnets of the vpc in every zone, and
+// keeps the one with the most available ip addresses for each of them.
+func (s *stepConfigSubnet) reuseSubnets(ctx context.Context, state multistep.StateBag,
+	vpcId string, zones []string) multistep.StepAction {
+	vpcClient := state.Get("vpc_client").(*vpc.Client)
+
+	Say(state, vpcId, "Trying to use existing subnets of vpc")
+
+	req := vpc.NewDescribeSubnetsRequest()
+	req.Filters = []*vpc.Filter{
+		{
+			Name:   common.StringPtr("vpc-id"),
+			Values: []*string{&vpcId},
+		},
+	}
+	subnets, err := GetSubnets(ctx, vpcClient, req)
+	if err != nil {
+		return Halt(state, err, "Failed to get subnet info")
+	}
+
+	best := make(map[string]*vpc.Subnet)
+	for _, subnet := range subnets {
+		current, ok := best[*subnet.Zone]
+		if !ok || *subnet.AvailableIpAddressCount > *current.AvailableIpAddressCount {
+			best[*subnet.Zone] = subnet
+		}
+	}
+
+	subnetIds := make(map[string]string, len(zones))
+	var subnetZones []string
+	for _, zone := range zones {
+		if subnet, ok := best[zone]; ok {
+			subnetIds[zone] = *subnet.SubnetId
+			subnetZones = append(subnetZones, zone)
+			Message(state, fmt.Sprintf("%s(%s) in zone %s", *subnet.SubnetName, *subnet.SubnetId, zone), "Subnet found")
+		}
+	}
+
+	if len(subnetZones) == 0 {
+		return Halt(state, fmt.Errorf("The specified vpc(%s) has no subnet in zones(%s)",
+			vpcId, strings.Join(zones, ",")), "")
+	}
+
+	state.Put("subnet_id", subnetIds[subnetZones[0]])
+	state.Put("subnet_ids", subnetIds)
+	state.Put("zones", subnetZones)
 
 	return multistep.ActionContinue
 }
 
 func (s *stepConfigSubnet) Cleanup(state multistep.StateBag) {
-	if !s.isCreate {
+	if len(s.createdSubnets) == 0 {
 		return
 	}
 
@@ -85,13 +196,15 @@ func (s *stepConfigSubnet) Cleanup(state multistep.StateBag) {
 
 	SayClean(state, "subnet")
 
-	req := vpc.NewDeleteSubnetRequest()
-	req.SubnetId = &s.SubnetId
-	err := Retry(ctx, func(ctx context.Context) error {
-		_, e := vpcClient.DeleteSubnet(req)
-		return e
-	})
-	if err != nil {
-		Error(state, err, fmt.Sprintf("Failed to delete subnet(%s), please delete it manually", s.SubnetId))
+	for _, subnetId := range s.createdSubnets {
+		req := vpc.NewDeleteSubnetRequest()
+		req.SubnetId = &subnetId
+		err := Retry(ctx, func(ctx context.Context) error {
+			_, e := vpcClient.DeleteSubnet(req)
+			return e
+		})
+		if err != nil {
+			Error(state, err, fmt.Sprintf("Failed to delete subnet(%s), please delete it manually", subnetId))
+		}
 	}
 }
@@ -0,0 +1,34 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package cvm
+
+import (
+	"testing"
+
+	"github.com/hashicorp/packer-plugin-sdk/multistep"
+	"github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common"
+	vpc "github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/vpc/v20170312"
+)
+
+func TestUseSubnet(t *testing.T) {
+	subnet := &vpc.Subnet{
+		SubnetId: common.StringPtr("subnet-00000001"),
+		Zone:     common.StringPtr("ap-guangzhou-3"),
+	}
+
+	state := new(multistep.BasicStateBag)
+	if err := useSubnet(state, subnet, []string{"ap-guangzhou-4", "ap-guangzhou-6"}); err == nil {
+		t.Fatal("should have error: zone does not offer the instance types")
+	}
+
+	if err := useSubnet(state, subnet, []string{"ap-guangzhou-3", "ap-guangzhou-4"}); err != nil {
+		t.Fatalf("shouldn't have error: %v", err)
+	}
+	if zones := state.Get("zones").([]string); len(zones) != 1 || zones[0] != "ap-guangzhou-3" {
+		t.Fatalf("zones should be the zone of the subnet, got %v", zones)
+	}
+	if subnetId := state.Get("subnet_ids").(map[string]string)["ap-guangzhou-3"]; subnetId != "subnet-00000001" {
+		t.Fatalf("unexpected subnet_ids: %v", state.Get("subnet_ids"))
+	}
+}
@@ -13,6 +13,7 @@ import (
 
 type stepConfigVPC struct {
 	VpcId     string
+	VpcFilter tencentCloudResourceFilter
 	CidrBlock string
 	VpcName   string
 	isCreate  bool
@@ -43,6 +44,33 @@ func (s *stepConfigVPC) Run(ctx context.Context, state multistep.StateBag) multi
 		return Halt(state, fmt.Errorf("The specified vpc(%s) does not exist", s.VpcId), "")
 	}
 
+	if !s.VpcFilter.Empty() {
+		Say(state, "Trying to find existing vpc by vpc_filter", "")
+		req := vpc.NewDescribeVpcsRequest()
+		req.Filters = s.VpcFilter.describeFilters("vpc-name")
+		vpcs, err := GetVpcs(ctx, vpcClient, req)
+		if err != nil {
+			return Halt(state, err, "Failed to get vpc info")
+		}
+		var matched []*vpc.Vpc
+		for _, v := range vpcs {
+			if s.VpcFilter.matchName(v.VpcName) {
+				matched = append(matched, v)
+			}
+		}
+		if len(matched) == 0 {
+			return Halt(state, fmt.Errorf("No vpc found using the specified vpc_filter"), "")
+		}
+		if len(matched) > 1 {
+			return Halt(state, fmt.Errorf("The specified vpc_filter matches %d vpcs, "+
+				"please try a more specific filter", len(matched)), "")
+		}
+		s.isCreate = false
+		state.Put("vpc_id", *matched[0].VpcId)
+		Message(state, fmt.Sprintf("%s(%s)", *matched[0].VpcName, *matched[0].VpcId), "Vpc found")
+		return multistep.ActionContinue
+	}
+
 	Say(state, "Trying to create a new vpc", "")
 
 	req := vpc.NewCreateVpcRequest()
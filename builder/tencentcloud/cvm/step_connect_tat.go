@@ -0,0 +1,82 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package cvm
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/packer-plugin-sdk/multistep"
+	"github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common"
+)
+
+type stepConnectTat struct {
+	Username       string
+	CommandTimeout time.Duration
+	AgentTimeout   time.Duration
+}
+
+func (s *stepConnectTat) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
+	client := state.Get("tat_client").(*TatClient)
+	instanceId := state.Get("instance_id").(string)
+
+	Say(state, instanceId, "Waiting for tat agent of instance to be online")
+
+	agent, err := s.waitForAgent(ctx, client, instanceId)
+	if err != nil {
+		return Halt(state, err, "Failed to wait for tat agent")
+	}
+
+	commandType := tatCommandTypeShell
+	if agent.Environment != nil && *agent.Environment == "Windows" {
+		commandType = tatCommandTypePowerShell
+	}
+
+	state.Put("communicator", &tatCommunicator{
+		client:       client,
+		instanceId:   instanceId,
+		commandType:  commandType,
+		username:     s.Username,
+		timeout:      s.CommandTimeout,
+		pollInterval: DefaultWaitForInterval * time.Second,
+	})
+	Message(state, fmt.Sprintf("Using tat communicator with %s commands", commandType), "")
+
+	return multistep.ActionContinue
+}
+
+func (s *stepConnectTat) waitForAgent(ctx context.Context, client *TatClient,
+	instanceId string) (*tatAutomationAgentInfo, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.AgentTimeout)
+	defer cancel()
+
+	req := newTatDescribeAutomationAgentStatusRequest()
+	req.InstanceIds = []*string{common.StringPtr(instanceId)}
+	for {
+		var resp *tatDescribeAutomationAgentStatusResponse
+		err := Retry(ctx, func(ctx context.Context) error {
+			var e error
+			resp, e = client.describeAutomationAgentStatus(ctx, req)
+			return e
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, agent := range resp.Response.AutomationAgentSet {
+			if agent.AgentStatus != nil && *agent.AgentStatus == "Online" {
+				return agent, nil
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("tat agent of instance(%s) is not online after %s", instanceId, s.AgentTimeout)
+		case <-time.After(DefaultWaitForInterval * time.Second):
+		}
+	}
+}
+
+func (s *stepConnectTat) Cleanup(multistep.StateBag) {}
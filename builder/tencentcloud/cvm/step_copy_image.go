@@ -6,16 +6,49 @@ package cvm
 import (
 	"context"
 	"fmt"
+	"sort"
 	"strings"
+	"sync"
 
 	"github.com/hashicorp/packer-plugin-sdk/multistep"
 	"github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common"
 	cvm "github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/cvm/v20170312"
+	sts "github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/sts/v20180813"
 )
 
 type stepCopyImage struct {
 	DesinationRegions []string
 	SourceRegion      string
+	AccessConfig      *TencentCloudAccessConfig
+	ImageName         string
+	AllowPartial      bool
+	RegionImages      map[string]tencentCloudRegionImage
+	ImageTags         map[string]string
+	copyRegions       []string
+	copiedImages      sync.Map
+	tagClient         *TagClient
+	uin               string
+}
+
+// NewStepCopyImage returns the step copying the image in state to the
+// destination regions, for builders creating the image their own way.
+func NewStepCopyImage(accessConfig *TencentCloudAccessConfig, imageConfig *TencentCloudImageConfig) multistep.Step {
+	return &stepCopyImage{
+		DesinationRegions: imageConfig.ImageCopyRegions,
+		SourceRegion:      accessConfig.Region,
+		AccessConfig:      accessConfig,
+		ImageName:         imageConfig.ImageName,
+		AllowPartial:      imageConfig.ImageCopyAllowPartial,
+		RegionImages:      imageConfig.RegionImages(),
+		ImageTags:         imageConfig.ImageTags,
+	}
+}
+
+// copyResult is the outcome of copying the image to a region.
+type copyResult struct {
+	region  string
+	imageId string
+	err     error
 }
 
 func (s *stepCopyImage) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
@@ -23,21 +56,34 @@ func (s *stepCopyImage) Run(ctx context.Context, state multistep.StateBag) multi
 		return multistep.ActionContinue
 	}
 
-	config := state.Get("config").(*Config)
 	client := state.Get("cvm_client").(*cvm.Client)
 
 	imageId := state.Get("image").(*cvm.Image).ImageId
 
-	Say(state, strings.Join(s.DesinationRegions, ","), "Trying to copy image to")
-
-	req := cvm.NewSyncImagesRequest()
-	req.ImageIds = []*string{imageId}
 	copyRegions := make([]*string, 0, len(s.DesinationRegions))
 	for _, region := range s.DesinationRegions {
 		if region != s.SourceRegion {
 			copyRegions = append(copyRegions, common.StringPtr(region))
 		}
 	}
+
+	Say(state, "Trying to check image name in destination regions", "")
+	for _, region := range copyRegions {
+		if err := s.checkImageName(ctx, *region); err != nil {
+			return Halt(state, err, "")
+		}
+	}
+
+	if len(s.ImageTags) > 0 {
+		if err := s.initTagging(ctx); err != nil {
+			return Halt(state, err, "Failed to init tagging of copied images")
+		}
+	}
+
+	Say(state, strings.Join(s.DesinationRegions, ","), "Trying to copy image to")
+
+	req := cvm.NewSyncImagesRequest()
+	req.ImageIds = []*string{imageId}
 	req.DestinationRegions = copyRegions
 
 	err := Retry(ctx, func(ctx context.Context) error {
@@ -47,54 +93,268 @@ func (s *stepCopyImage) Run(ctx context.Context, state multistep.StateBag) multi
 	if err != nil {
 		return Halt(state, err, "Failed to copy image")
 	}
+	for _, region := range copyRegions {
+		s.copyRegions = append(s.copyRegions, *region)
+	}
 
-	Message(state, "Waiting for image ready", "")
+	Message(state, fmt.Sprintf("Waiting for image ready in %d regions", len(copyRegions)), "")
 	tencentCloudImages := state.Get("tencentcloudimages").(map[string]string)
 
-	cf := &TencentCloudAccessConfig{
-		SecretId:      config.SecretId,
-		SecretKey:     config.SecretKey,
-		Zone:          config.Zone,
-		CvmEndpoint:   config.CvmEndpoint,
-		SecurityToken: config.SecurityToken,
-		AssumeRole: TencentCloudAccessRole{
-			RoleArn:         config.AssumeRole.RoleArn,
-			SessionName:     config.AssumeRole.SessionName,
-			SessionDuration: config.AssumeRole.SessionDuration,
-		},
-		Profile:              config.Profile,
-		SharedCredentialsDir: config.SharedCredentialsDir,
-	}
-
-	for _, region := range req.DestinationRegions {
-		cf.Region = *region
-		rc, err := NewCvmClient(cf)
-		if err != nil {
-			return Halt(state, err, "Failed to init client")
+	results := make(chan copyResult, len(copyRegions))
+	var wg sync.WaitGroup
+	for _, region := range copyRegions {
+		wg.Add(1)
+		go func(region string) {
+			defer wg.Done()
+			imageId, err := s.waitForCopy(ctx, region)
+			results <- copyResult{region: region, imageId: imageId, err: err}
+		}(*region)
+	}
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	failed := make(map[string]error)
+	done := 0
+	for result := range results {
+		done++
+		progress := fmt.Sprintf("[%d/%d] ", done, len(copyRegions))
+		if result.err != nil {
+			failed[result.region] = result.err
+			Message(state, fmt.Sprintf("%sFailed to copy image to %s: %s", progress, result.region, result.err), "")
+			continue
+		}
+
+		tencentCloudImages[result.region] = result.imageId
+		Message(state, fmt.Sprintf("%sCopy image from %s(%s) to %s(%s)",
+			progress, s.SourceRegion, *imageId, result.region, result.imageId), "")
+	}
+
+	state.Put("tencentcloudimages", tencentCloudImages)
+
+	if len(failed) > 0 {
+		var failures []string
+		for region, err := range failed {
+			failures = append(failures, fmt.Sprintf("%s: %s", region, err))
+		}
+		if !s.AllowPartial {
+			sort.Strings(failures)
+			err := fmt.Errorf("failed to copy image to %d of %d regions:\n%s",
+				len(failures), len(copyRegions), strings.Join(failures, "\n"))
+			return Halt(state, err, "")
 		}
 
-		err = WaitForImageReady(ctx, rc, config.ImageName, "NORMAL", 1800)
+		// The build goes on without the failed regions, so the image left
+		// in them is deleted now.
+		failures = failures[:0]
+		var copied []string
+		for _, region := range s.copyRegions {
+			copyErr, ok := failed[region]
+			if !ok {
+				copied = append(copied, region)
+				continue
+			}
+			failure := fmt.Sprintf("%s: %s", region, copyErr)
+			imageId, err := s.deleteCopiedImage(ctx, region)
+			switch {
+			case err != nil:
+				failure += fmt.Sprintf(", failed to delete the image left there, please delete it manually: %s", err)
+				copied = append(copied, region)
+			case imageId != "":
+				failure += fmt.Sprintf(", image(%s) left there deleted", imageId)
+			}
+			failures = append(failures, failure)
+		}
+		s.copyRegions = copied
+
+		sort.Strings(failures)
+		err := fmt.Errorf("failed to copy image to %d of %d regions:\n%s",
+			len(failures), len(copyRegions), strings.Join(failures, "\n"))
+		Error(state, err, "Image partially copied")
+		return multistep.ActionContinue
+	}
+
+	Message(state, "Image copied", "")
+
+	return multistep.ActionContinue
+}
+
+// checkImageName checks both the name the image is copied with and its name
+// in region are not used yet.
+func (s *stepCopyImage) checkImageName(ctx context.Context, region string) error {
+	rc, err := newRegionCvmClient(s.AccessConfig, region)
+	if err != nil {
+		return fmt.Errorf("failed to init client of region(%s): %s", region, err)
+	}
+
+	names := []string{s.ImageName}
+	if name := s.RegionImages[region].ImageName; name != "" && name != s.ImageName {
+		names = append(names, name)
+	}
+
+	for _, name := range names {
+		image, err := GetImageByName(ctx, rc, name)
 		if err != nil {
-			return Halt(state, err, "Failed to wait for image ready")
+			return fmt.Errorf("failed to get images info of region(%s): %s", region, err)
+		}
+		if image != nil {
+			return fmt.Errorf("Image name %s has exists in region(%s)", name, region)
 		}
+	}
 
-		image, err := GetImageByName(ctx, rc, config.ImageName)
+	return nil
+}
+
+// waitForCopy waits for the image copied to region to be ready, then applies
+// its name, description and tags there. It returns the id of the image once
+// known, even on failure.
+func (s *stepCopyImage) waitForCopy(ctx context.Context, region string) (string, error) {
+	rc, err := newRegionCvmClient(s.AccessConfig, region)
+	if err != nil {
+		return "", fmt.Errorf("failed to init client: %s", err)
+	}
+
+	err = WaitForImageReady(ctx, rc, s.ImageName, "NORMAL", 1800)
+	if err != nil {
+		return "", fmt.Errorf("failed to wait for image ready: %s", err)
+	}
+
+	image, err := GetImageByName(ctx, rc, s.ImageName)
+	if err != nil {
+		return "", fmt.Errorf("failed to get image: %s", err)
+	}
+	if image == nil {
+		return "", fmt.Errorf("image(%s) not found", s.ImageName)
+	}
+	imageId := *image.ImageId
+	s.copiedImages.Store(region, imageId)
+
+	if override, ok := s.RegionImages[region]; ok && (override.ImageName != "" || override.ImageDescription != "") {
+		req := cvm.NewModifyImageAttributeRequest()
+		req.ImageId = &imageId
+		if override.ImageName != "" {
+			req.ImageName = &override.ImageName
+		}
+		if override.ImageDescription != "" {
+			req.ImageDescription = &override.ImageDescription
+		}
+		err := Retry(ctx, func(ctx context.Context) error {
+			_, e := rc.ModifyImageAttribute(req)
+			return e
+		})
 		if err != nil {
-			return Halt(state, err, "Failed to get image")
+			return imageId, fmt.Errorf("failed to modify image(%s) attribute: %s", imageId, err)
 		}
+	}
 
-		if image == nil {
-			return Halt(state, err, "Failed to wait for image ready")
+	if len(s.ImageTags) > 0 {
+		if err := s.tagImage(ctx, region, imageId); err != nil {
+			return imageId, fmt.Errorf("failed to tag image(%s): %s", imageId, err)
 		}
+	}
 
-		tencentCloudImages[*region] = *image.ImageId
-		Message(state, fmt.Sprintf("Copy image from %s(%s) to %s(%s)", s.SourceRegion, *imageId, *region, *image.ImageId), "")
+	return imageId, nil
+}
+
+// initTagging creates the tag client, and looks up the account the images
+// belong to, which the resource description of the images is made of.
+func (s *stepCopyImage) initTagging(ctx context.Context) error {
+	tagClient, err := NewTagClient(s.AccessConfig)
+	if err != nil {
+		return err
+	}
+	stsClient, err := NewStsClient(s.AccessConfig)
+	if err != nil {
+		return err
 	}
 
-	state.Put("tencentcloudimages", tencentCloudImages)
-	Message(state, "Image copied", "")
+	var uin string
+	err = Retry(ctx, func(ctx context.Context) error {
+		resp, e := stsClient.GetCallerIdentityWithContext(ctx, sts.NewGetCallerIdentityRequest())
+		if e != nil {
+			return e
+		}
+		if resp.Response.AccountId != nil {
+			uin = *resp.Response.AccountId
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if uin == "" {
+		return fmt.Errorf("no account id found of the credentials")
+	}
 
-	return multistep.ActionContinue
+	s.tagClient = tagClient
+	s.uin = uin
+
+	return nil
 }
 
-func (s *stepCopyImage) Cleanup(state multistep.StateBag) {}
+// tagImage applies the image tags to the image copied to region, which
+// SyncImages leaves untagged.
+func (s *stepCopyImage) tagImage(ctx context.Context, region string, imageId string) error {
+	resource := fmt.Sprintf("qcs::cvm:%s:uin/%s:image/%s", region, s.uin, imageId)
+	return s.tagClient.modifyResourceTags(ctx, resource, s.ImageTags)
+}
+
+func (s *stepCopyImage) Cleanup(state multistep.StateBag) {
+	if len(s.copyRegions) == 0 {
+		return
+	}
+
+	_, cancelled := state.GetOk(multistep.StateCancelled)
+	_, halted := state.GetOk(multistep.StateHalted)
+	if !cancelled && !halted {
+		return
+	}
+
+	ctx := context.TODO()
+
+	SayClean(state, "copied images")
+
+	for _, region := range s.copyRegions {
+		if _, err := s.deleteCopiedImage(ctx, region); err != nil {
+			Error(state, err, fmt.Sprintf("Failed to delete image(%s) in region(%s), please delete it manually",
+				s.ImageName, region))
+		}
+	}
+}
+
+// deleteCopiedImage deletes the image copied to region, and returns its id,
+// empty if there is none.
+func (s *stepCopyImage) deleteCopiedImage(ctx context.Context, region string) (string, error) {
+	rc, err := newRegionCvmClient(s.AccessConfig, region)
+	if err != nil {
+		return "", fmt.Errorf("failed to init client: %s", err)
+	}
+
+	var imageId string
+	if id, ok := s.copiedImages.Load(region); ok {
+		imageId = id.(string)
+	} else {
+		// The copy may not be done yet, so it is found by name.
+		image, err := GetImageByName(ctx, rc, s.ImageName)
+		if err != nil {
+			return "", fmt.Errorf("failed to get image: %s", err)
+		}
+		if image == nil {
+			return "", nil
+		}
+		imageId = *image.ImageId
+	}
+
+	req := cvm.NewDeleteImagesRequest()
+	req.ImageIds = []*string{&imageId}
+	err = Retry(ctx, func(ctx context.Context) error {
+		_, e := rc.DeleteImages(req)
+		return e
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return imageId, nil
+}
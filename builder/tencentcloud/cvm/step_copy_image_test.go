@@ -0,0 +1,191 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package cvm
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/packer-plugin-sdk/multistep"
+	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
+	"github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common"
+	cvm "github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/cvm/v20170312"
+)
+
+// newFakeCopyCvm starts a fake copying images with the state of their
+// region, and returns the names of the images in every region with it.
+func newFakeCopyCvm(t *testing.T, states map[string]string) (*fakeCvm, *TencentCloudAccessConfig, map[string]string) {
+	names := make(map[string]string)
+	fake, accessConfig := newFakeCvm(t, map[string]fakeCvmHandler{
+		"SyncImages": func(call fakeCvmCall) interface{} {
+			var req struct{ DestinationRegions []string }
+			call.decode(&req)
+			for _, region := range req.DestinationRegions {
+				names[region] = "packer-test"
+			}
+			return nil
+		},
+		"DescribeImages": func(call fakeCvmCall) interface{} {
+			var req struct{ Filters []struct{ Values []string } }
+			call.decode(&req)
+			images := []map[string]string{}
+			if name, ok := names[call.Region]; ok && len(req.Filters) > 0 && req.Filters[0].Values[0] == name {
+				images = append(images, map[string]string{
+					"ImageId":    "img-" + call.Region,
+					"ImageName":  name,
+					"ImageState": states[call.Region],
+				})
+			}
+			return map[string]interface{}{"ImageSet": images, "TotalCount": len(images)}
+		},
+		"ModifyImageAttribute": func(call fakeCvmCall) interface{} {
+			var req struct{ ImageName string }
+			call.decode(&req)
+			names[call.Region] = req.ImageName
+			return nil
+		},
+		"GetCallerIdentity": func(fakeCvmCall) interface{} {
+			return map[string]string{"AccountId": "100000000001"}
+		},
+		"ModifyResourceTags": func(fakeCvmCall) interface{} { return nil },
+		"DeleteImages":       func(fakeCvmCall) interface{} { return nil },
+	})
+
+	return fake, accessConfig, names
+}
+
+func testCopyImageState(t *testing.T, accessConfig *TencentCloudAccessConfig) multistep.StateBag {
+	client, err := NewCvmClient(accessConfig)
+	if err != nil {
+		t.Fatalf("shouldn't have err: %v", err)
+	}
+
+	state := new(multistep.BasicStateBag)
+	state.Put("ui", packersdk.TestUi(t))
+	state.Put("cvm_client", client)
+	state.Put("image", &cvm.Image{ImageId: common.StringPtr("img-source")})
+	state.Put("tencentcloudimages", map[string]string{"ap-guangzhou": "img-source"})
+
+	return state
+}
+
+func TestStepCopyImage(t *testing.T) {
+	for _, allowPartial := range []bool{false, true} {
+		fake, accessConfig, _ := newFakeCopyCvm(t, map[string]string{"ap-shanghai": "NORMAL", "ap-beijing": "SYNC_FAILED"})
+		state := testCopyImageState(t, accessConfig)
+
+		step := NewStepCopyImage(accessConfig, &TencentCloudImageConfig{
+			ImageName:             "packer-test",
+			ImageCopyRegions:      []string{"ap-shanghai", "ap-beijing"},
+			ImageCopyAllowPartial: allowPartial,
+		})
+		action := step.Run(context.Background(), state)
+
+		images := state.Get("tencentcloudimages").(map[string]string)
+		if images["ap-shanghai"] != "img-ap-shanghai" {
+			t.Fatalf("copied image should be kept: %v", images)
+		}
+		if _, ok := images["ap-beijing"]; ok {
+			t.Fatalf("failed copy shouldn't be kept: %v", images)
+		}
+
+		if allowPartial && action != multistep.ActionContinue {
+			t.Fatal("should continue on partial copy")
+		}
+		if !allowPartial && action != multistep.ActionHalt {
+			t.Fatal("should halt on partial copy")
+		}
+		if !allowPartial {
+			continue
+		}
+
+		// The image left in the failed region is deleted right away, and
+		// not again when a later step fails.
+		if deleted := strings.Join(fake.regions("DeleteImages"), ","); deleted != "ap-beijing" {
+			t.Fatalf("image left in the failed region should be deleted: %s", deleted)
+		}
+		state.Put(multistep.StateHalted, true)
+		step.Cleanup(state)
+		if deleted := strings.Join(fake.regions("DeleteImages"), ","); deleted != "ap-beijing,ap-shanghai" {
+			t.Fatalf("every image should be deleted once: %s", deleted)
+		}
+	}
+}
+
+func TestStepCopyImage_RegionImages(t *testing.T) {
+	fake, accessConfig, names := newFakeCopyCvm(t, map[string]string{"ap-shanghai": "NORMAL", "ap-beijing": "NORMAL"})
+	state := testCopyImageState(t, accessConfig)
+
+	step := NewStepCopyImage(accessConfig, &TencentCloudImageConfig{
+		ImageName:        "packer-test",
+		ImageCopyRegions: []string{"ap-shanghai", "ap-beijing"},
+		ImageRegionOverrides: []tencentCloudRegionImage{
+			{Region: "ap-beijing", ImageName: "packer-test-beijing"},
+		},
+		ImageTags: map[string]string{"team": "infra"},
+	})
+	if action := step.Run(context.Background(), state); action != multistep.ActionContinue {
+		t.Fatal("should continue")
+	}
+
+	if calls := len(fake.callsOf("GetCallerIdentity")); calls != 1 {
+		t.Fatalf("account should be looked up once: %d", calls)
+	}
+	if names["ap-beijing"] != "packer-test-beijing" || names["ap-shanghai"] != "packer-test" {
+		t.Fatalf("invalid image names: %v", names)
+	}
+
+	var tagged []string
+	for _, call := range fake.callsOf("ModifyResourceTags") {
+		var req struct {
+			Resource    string
+			ReplaceTags []struct{ TagKey, TagValue string }
+		}
+		call.decode(&req)
+		for _, tag := range req.ReplaceTags {
+			tagged = append(tagged, req.Resource+":"+tag.TagKey+"="+tag.TagValue)
+		}
+	}
+	sort.Strings(tagged)
+	expected := "qcs::cvm:ap-beijing:uin/100000000001:image/img-ap-beijing:team=infra," +
+		"qcs::cvm:ap-shanghai:uin/100000000001:image/img-ap-shanghai:team=infra"
+	if strings.Join(tagged, ",") != expected {
+		t.Fatalf("copied images should be tagged: %v", tagged)
+	}
+
+	// The names are used now, so copying again fails before SyncImages.
+	state = testCopyImageState(t, accessConfig)
+	step = NewStepCopyImage(accessConfig, &TencentCloudImageConfig{
+		ImageName:        "packer-test",
+		ImageCopyRegions: []string{"ap-shanghai"},
+	})
+	if action := step.Run(context.Background(), state); action != multistep.ActionHalt {
+		t.Fatal("should halt when the image name is used")
+	}
+	if synced := len(fake.callsOf("SyncImages")); synced != 1 {
+		t.Fatal("image shouldn't be copied when the image name is used")
+	}
+}
+
+func TestStepCopyImage_Cleanup(t *testing.T) {
+	fake, accessConfig, _ := newFakeCopyCvm(t, map[string]string{"ap-shanghai": "NORMAL", "ap-beijing": "SYNC_FAILED"})
+	state := testCopyImageState(t, accessConfig)
+
+	step := NewStepCopyImage(accessConfig, &TencentCloudImageConfig{
+		ImageName:        "packer-test",
+		ImageCopyRegions: []string{"ap-guangzhou", "ap-shanghai", "ap-beijing"},
+	})
+	if action := step.Run(context.Background(), state); action != multistep.ActionHalt {
+		t.Fatal("should halt on partial copy")
+	}
+
+	state.Put(multistep.StateHalted, true)
+	step.Cleanup(state)
+
+	if deleted := strings.Join(fake.regions("DeleteImages"), ","); deleted != "ap-beijing,ap-shanghai" {
+		t.Fatalf("copied images should be deleted: %s", deleted)
+	}
+}
@@ -28,11 +28,11 @@ func (s *stepCreateImage) Run(ctx context.Context, state multistep.StateBag) mul
 	req.ImageDescription = &config.ImageDescription
 	req.InstanceId = instance.InstanceId
 
-	// TODO: We should allow user to specify which data disk should be
-	// included into created image.
-	var dataDiskIds []*string
-	for _, disk := range instance.DataDisks {
-		dataDiskIds = append(dataDiskIds, disk.DiskId)
+	requested, _ := state.Get("data_disks").([]*cvm.DataDisk)
+	mountTags, _ := state.Get("data_disk_mount_tags").([]string)
+	dataDiskIds, err := selectImageDataDisks(&config.TencentCloudRunConfig, requested, instance.DataDisks, mountTags)
+	if err != nil {
+		return Halt(state, err, "Failed to select image data disks")
 	}
 	if len(dataDiskIds) > 0 {
 		req.DataDiskIds = dataDiskIds
@@ -72,7 +72,7 @@ func (s *stepCreateImage) Run(ctx context.Context, state multistep.StateBag) mul
 		}
 	}
 
-	err := Retry(ctx, func(ctx context.Context) error {
+	err = Retry(ctx, func(ctx context.Context) error {
 		_, e := client.CreateImage(req)
 		return e
 	})
@@ -132,3 +132,87 @@ func (s *stepCreateImage) Cleanup(state multistep.StateBag) {
 		Error(state, err, fmt.Sprintf("Failed to delete image(%s), please delete it manually", s.imageId))
 	}
 }
+
+// selectImageDataDisks returns the ids of the data disks included into the
+// image, which are all of them unless image_data_disks or
+// image_system_disk_only is set. The data disks of the instance are selected
+// by the position of the requested data disk they were launched from, so
+// they must match the requested ones in the same order.
+func selectImageDataDisks(cf *TencentCloudRunConfig, requested []*cvm.DataDisk, disks []*cvm.DataDisk,
+	mountTags []string) ([]*string, error) {
+	if cf.ImageSystemDiskOnly {
+		return nil, nil
+	}
+
+	var dataDiskIds []*string
+	if len(cf.ImageDataDisks) == 0 {
+		for _, disk := range disks {
+			dataDiskIds = append(dataDiskIds, disk.DiskId)
+		}
+		return dataDiskIds, nil
+	}
+
+	if err := checkDataDisks(requested, disks); err != nil {
+		return nil, err
+	}
+
+	matched := make([]bool, len(cf.ImageDataDisks))
+	for i, disk := range disks {
+		var mountTag string
+		if i < len(mountTags) {
+			mountTag = mountTags[i]
+		}
+
+		selected := false
+		for j := range cf.ImageDataDisks {
+			if cf.ImageDataDisks[j].match(i, disk, mountTag) {
+				matched[j] = true
+				selected = true
+			}
+		}
+		if selected {
+			dataDiskIds = append(dataDiskIds, disk.DiskId)
+		}
+	}
+
+	for j, ok := range matched {
+		if !ok {
+			return nil, fmt.Errorf("image_data_disks[%d] matches no data disk of the instance", j)
+		}
+	}
+
+	return dataDiskIds, nil
+}
+
+// checkDataDisks fails unless every data disk of the instance has the
+// snapshot, and the type and size if set, of the requested data disk at the
+// same position.
+func checkDataDisks(requested []*cvm.DataDisk, disks []*cvm.DataDisk) error {
+	if len(disks) != len(requested) {
+		return fmt.Errorf("the instance has %d data disks, %d requested", len(disks), len(requested))
+	}
+
+	for i, disk := range disks {
+		want := requested[i]
+		if !sameString(want.SnapshotId, disk.SnapshotId) ||
+			(want.DiskType != nil && !sameString(want.DiskType, disk.DiskType)) ||
+			(want.DiskSize != nil && (disk.DiskSize == nil || *disk.DiskSize != *want.DiskSize)) {
+			return fmt.Errorf("data disk %d(%s) of the instance does not match the requested one", i, *disk.DiskId)
+		}
+	}
+
+	return nil
+}
+
+// sameString tells whether got has the wanted value, an unset one matching
+// an empty value.
+func sameString(want *string, got *string) bool {
+	var w, g string
+	if want != nil {
+		w = *want
+	}
+	if got != nil {
+		g = *got
+	}
+	return w == g
+}
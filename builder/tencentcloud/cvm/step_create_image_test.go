@@ -0,0 +1,71 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package cvm
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common"
+	cvm "github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/cvm/v20170312"
+)
+
+func TestSelectImageDataDisks(t *testing.T) {
+	disks := []*cvm.DataDisk{
+		{DiskId: common.StringPtr("disk-data0000"), DiskSize: common.Int64Ptr(100)},
+		{DiskId: common.StringPtr("disk-data0001"), DiskSize: common.Int64Ptr(200)},
+		{DiskId: common.StringPtr("disk-cache000"), DiskSize: common.Int64Ptr(500)},
+	}
+	requested := []*cvm.DataDisk{
+		{DiskSize: common.Int64Ptr(100)},
+		{DiskSize: common.Int64Ptr(200)},
+		{DiskSize: common.Int64Ptr(500)},
+	}
+	mountTags := []string{"", "data", "cache"}
+	index := 0
+
+	cases := []struct {
+		cf       TencentCloudRunConfig
+		expected []string
+	}{
+		{TencentCloudRunConfig{}, []string{"disk-data0000", "disk-data0001", "disk-cache000"}},
+		{TencentCloudRunConfig{ImageSystemDiskOnly: true}, nil},
+		{TencentCloudRunConfig{ImageDataDisks: []tencentCloudImageDataDisk{
+			{Index: &index},
+			{MountTag: "data"},
+		}}, []string{"disk-data0000", "disk-data0001"}},
+		{TencentCloudRunConfig{ImageDataDisks: []tencentCloudImageDataDisk{
+			{DiskSize: 500},
+		}}, []string{"disk-cache000"}},
+	}
+
+	for _, c := range cases {
+		dataDiskIds, err := selectImageDataDisks(&c.cf, requested, disks, mountTags)
+		if err != nil {
+			t.Fatalf("shouldn't have err: %v", err)
+		}
+		var ids []string
+		for _, id := range dataDiskIds {
+			ids = append(ids, *id)
+		}
+		if !reflect.DeepEqual(ids, c.expected) {
+			t.Fatalf("unexpected data disks: %v, expected %v", ids, c.expected)
+		}
+	}
+
+	cf := TencentCloudRunConfig{ImageDataDisks: []tencentCloudImageDataDisk{{MountTag: "scratch"}}}
+	if _, err := selectImageDataDisks(&cf, requested, disks, mountTags); err == nil {
+		t.Fatal("should have err: selector matches no data disk")
+	}
+
+	cf = TencentCloudRunConfig{ImageDataDisks: []tencentCloudImageDataDisk{{MountTag: "data"}}}
+	if _, err := selectImageDataDisks(&cf, requested[:2], disks, mountTags); err == nil {
+		t.Fatal("should have err: instance has more data disks than requested")
+	}
+
+	requested[1] = &cvm.DataDisk{DiskSize: common.Int64Ptr(200), SnapshotId: common.StringPtr("snap-00000001")}
+	if _, err := selectImageDataDisks(&cf, requested, disks, mountTags); err == nil {
+		t.Fatal("should have err: data disk doesn't match the requested one")
+	}
+}
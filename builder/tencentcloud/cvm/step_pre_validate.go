@@ -12,21 +12,27 @@ import (
 )
 
 type stepPreValidate struct {
+	ImageName string
+}
+
+// NewStepPreValidate returns the step checking the image name is not used
+// yet, for builders creating the image their own way.
+func NewStepPreValidate(imageName string) multistep.Step {
+	return &stepPreValidate{ImageName: imageName}
 }
 
 func (s *stepPreValidate) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
-	config := state.Get("config").(*Config)
 	client := state.Get("cvm_client").(*cvm.Client)
 
-	Say(state, config.ImageName, "Trying to check image name")
+	Say(state, s.ImageName, "Trying to check image name")
 
-	image, err := GetImageByName(ctx, client, config.ImageName)
+	image, err := GetImageByName(ctx, client, s.ImageName)
 	if err != nil {
 		return Halt(state, err, "Failed to get images info")
 	}
 
 	if image != nil {
-		return Halt(state, fmt.Errorf("Image name %s has exists", config.ImageName), "")
+		return Halt(state, fmt.Errorf("Image name %s has exists", s.ImageName), "")
 	}
 
 	Message(state, "useable", "Image name")
@@ -6,24 +6,28 @@ package cvm
 import (
 	"context"
 	"encoding/base64"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"log"
+	"strings"
 
 	"github.com/hashicorp/packer-plugin-sdk/multistep"
+	"github.com/hashicorp/packer-plugin-sdk/packerbuilderdata"
+	"github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common"
 	cvm "github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/cvm/v20170312"
 )
 
 type stepRunInstance struct {
-	InstanceType             string
+	InstanceTypes            []string
 	InstanceChargeType       string
 	UserData                 string
 	UserDataFile             string
 	instanceId               string
-	ZoneId                   string
 	InstanceName             string
 	DiskType                 string
 	DiskSize                 int64
+	DiskAutoGrow             bool
 	HostName                 string
 	InternetChargeType       string
 	InternetMaxBandwidthOut  int64
@@ -32,6 +36,12 @@ type stepRunInstance struct {
 	AssociatePublicIpAddress bool
 	Tags                     map[string]string
 	DataDisks                []tencentCloudDataDisk
+	SpotPrice                string
+	SpotInstanceType         string
+	SpotFallbackToPostpaid   bool
+	GeneratedData            *packerbuilderdata.GeneratedData
+	attempts                 int
+	dataDiskSources          []*tencentCloudDataDisk
 }
 
 func (s *stepRunInstance) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
@@ -40,8 +50,7 @@ func (s *stepRunInstance) Run(ctx context.Context, state multistep.StateBag) mul
 	config := state.Get("config").(*Config)
 	source_image := state.Get("source_image").(*cvm.Image)
 	vpc_id := state.Get("vpc_id").(string)
-	subnet_id := state.Get("subnet_id").(string)
-	security_group_id := state.Get("security_group_id").(string)
+	security_group_ids := state.Get("security_group_ids").([]string)
 
 	password := config.Comm.SSHPassword
 	if password == "" && config.Comm.WinRMPassword != "" {
@@ -57,61 +66,34 @@ func (s *stepRunInstance) Run(ctx context.Context, state multistep.StateBag) mul
 
 	// config RunInstances parameters
 	req := cvm.NewRunInstancesRequest()
-	if s.ZoneId != "" {
-		req.Placement = &cvm.Placement{
-			Zone: &s.ZoneId,
-		}
+	req.ImageId = source_image.ImageId
+	diskSize, err := checkSystemDiskSize(source_image, s.DiskType, s.DiskSize, s.DiskAutoGrow)
+	if err != nil {
+		return Halt(state, err, "Invalid disk_size")
 	}
-	instanceChargeType := s.InstanceChargeType
-	if instanceChargeType == "" {
-		instanceChargeType = "POSTPAID_BY_HOUR"
+	if s.DiskSize != 0 && diskSize != s.DiskSize {
+		Message(state, fmt.Sprintf("%dGB", diskSize), "Grow system disk to source image size")
 	}
-	req.InstanceChargeType = &instanceChargeType
-	req.ImageId = source_image.ImageId
-	req.InstanceType = &s.InstanceType
-	// TODO: Add check for system disk size, it should be larger than image system disk size.
 	req.SystemDisk = &cvm.SystemDisk{
 		DiskType: &s.DiskType,
-		DiskSize: &s.DiskSize,
-	}
-	// System disk snapshot is mandatory, so if there are additional data disks,
-	// length will be larger than 1.
-	if len(source_image.SnapshotSet) > 1 {
-		Message(state, "Use source image snapshot data disks, ignore user data disk settings", "")
-		var dataDisks []*cvm.DataDisk
-		for _, snapshot := range source_image.SnapshotSet {
-			if *snapshot.DiskUsage == "DATA_DISK" {
-				var dataDisk cvm.DataDisk
-				// FIXME: Currently we have no way to get original disk type
-				// from data disk snapshots, and we don't allow user to overwrite
-				// snapshot settings, and we cannot guarantee a certain hard-coded type
-				// is not sold out, so here we use system disk type as a workaround.
-				//
-				// Eventually, we need to allow user to overwrite snapshot disk
-				// settings.
-				dataDisk.DiskType = &s.DiskType
-				dataDisk.DiskSize = snapshot.DiskSize
-				dataDisk.SnapshotId = snapshot.SnapshotId
-				dataDisks = append(dataDisks, &dataDisk)
-			}
-		}
-		req.DataDisks = dataDisks
-	} else {
-		var dataDisks []*cvm.DataDisk
-		for _, disk := range s.DataDisks {
-			var dataDisk cvm.DataDisk
-			dataDisk.DiskType = &disk.DiskType
-			dataDisk.DiskSize = &disk.DiskSize
-			if disk.SnapshotId != "" {
-				dataDisk.SnapshotId = &disk.SnapshotId
-			}
-			dataDisks = append(dataDisks, &dataDisk)
+		DiskSize: &diskSize,
+	}
+	dataDisks, dataDiskSources, err := buildDataDisks(source_image, s.DataDisks, s.DiskType)
+	if err != nil {
+		return Halt(state, err, "Invalid data disks")
+	}
+	req.DataDisks = dataDisks
+	s.dataDiskSources = dataDiskSources
+	mountTags := make([]string, len(dataDiskSources))
+	for i, disk := range dataDiskSources {
+		if disk != nil {
+			mountTags[i] = disk.MountTag
 		}
-		req.DataDisks = dataDisks
 	}
+	state.Put("data_disks", dataDisks)
+	state.Put("data_disk_mount_tags", mountTags)
 	req.VirtualPrivateCloud = &cvm.VirtualPrivateCloud{
-		VpcId:    &vpc_id,
-		SubnetId: &subnet_id,
+		VpcId: &vpc_id,
 	}
 	if s.AssociatePublicIpAddress {
 		req.InternetAccessible = &cvm.InternetAccessible{
@@ -134,8 +116,7 @@ func (s *stepRunInstance) Run(ctx context.Context, state multistep.StateBag) mul
 		loginSettings.KeyIds = []*string{&config.Comm.SSHKeyPairName}
 	}
 	req.LoginSettings = &loginSettings
-	req.SecurityGroupIds = []*string{&security_group_id}
-	req.ClientToken = &s.InstanceName
+	req.SecurityGroupIds = common.StringPtrs(security_group_ids)
 	req.HostName = &s.HostName
 	req.UserData = &userData
 	req.CamRoleName = &s.CamRoleName
@@ -158,26 +139,18 @@ func (s *stepRunInstance) Run(ctx context.Context, state multistep.StateBag) mul
 		}
 	}
 
-	var resp *cvm.RunInstancesResponse
-	err = Retry(ctx, func(ctx context.Context) error {
-		var e error
-		resp, e = client.RunInstances(req)
-		return e
-	})
-	if err != nil {
-		return Halt(state, err, "Failed to run instance")
+	instanceChargeType := s.InstanceChargeType
+	if instanceChargeType == "" {
+		instanceChargeType = "POSTPAID_BY_HOUR"
 	}
-
-	if len(resp.Response.InstanceIdSet) != 1 {
-		return Halt(state, fmt.Errorf("No instance return"), "Failed to run instance")
+	err = s.launchCandidates(ctx, state, req, instanceChargeType)
+	if err != nil && instanceChargeType == "SPOTPAID" && s.SpotFallbackToPostpaid && isSpotUnavailableError(err) {
+		Message(state, fmt.Sprintf("Spot instance is unavailable(%s), falling back to POSTPAID_BY_HOUR", err), "")
+		s.discardInstance(ctx, state)
+		err = s.launchCandidates(ctx, state, req, "POSTPAID_BY_HOUR")
 	}
-
-	s.instanceId = *resp.Response.InstanceIdSet[0]
-	Message(state, "Waiting for instance ready", "")
-
-	err = WaitForInstance(ctx, client, s.instanceId, "RUNNING", 1800)
 	if err != nil {
-		return Halt(state, err, "Failed to wait for instance ready")
+		return Halt(state, err, "Failed to run instance")
 	}
 
 	describeReq := cvm.NewDescribeInstancesRequest()
@@ -192,15 +165,297 @@ func (s *stepRunInstance) Run(ctx context.Context, state multistep.StateBag) mul
 		return Halt(state, err, "Failed to wait for instance ready")
 	}
 
-	state.Put("instance", describeResp.Response.InstanceSet[0])
+	instance := describeResp.Response.InstanceSet[0]
+	state.Put("instance", instance)
 	// instance_id is the generic term used so that users can have access to the
 	// instance id inside of the provisioners, used in step_provision.
 	state.Put("instance_id", s.instanceId)
 	Message(state, s.instanceId, "Instance created")
 
+	s.GeneratedData.Put("InstanceId", s.instanceId)
+	s.GeneratedData.Put("InstanceType", state.Get("instance_type"))
+	s.GeneratedData.Put("Zone", state.Get("zone"))
+	s.GeneratedData.Put("VpcId", vpc_id)
+	s.GeneratedData.Put("SubnetId", state.Get("subnet_id"))
+	s.GeneratedData.Put("SecurityGroupId", state.Get("security_group_id"))
+	s.GeneratedData.Put("PrivateIp", firstAddress(instance.PrivateIpAddresses))
+	s.GeneratedData.Put("PublicIp", firstAddress(instance.PublicIpAddresses))
+
 	return multistep.ActionContinue
 }
 
+// launchCandidates tries to launch the instance with every instance type
+// in every zone offering it in order, until one of them is not sold out.
+func (s *stepRunInstance) launchCandidates(ctx context.Context, state multistep.StateBag,
+	req *cvm.RunInstancesRequest, instanceChargeType string) error {
+	zones := state.Get("zones").([]string)
+	zoneInstanceTypes := state.Get("zone_instance_types").(map[string][]string)
+	subnetIds := state.Get("subnet_ids").(map[string]string)
+
+	var err error
+	for _, instanceType := range s.InstanceTypes {
+		for _, zone := range zones {
+			subnetId, ok := subnetIds[zone]
+			if !ok || !containsString(zoneInstanceTypes[zone], instanceType) {
+				continue
+			}
+
+			if len(s.InstanceTypes) > 1 || len(subnetIds) > 1 {
+				Message(state, fmt.Sprintf("%s in zone %s", instanceType, zone), "Trying instance type")
+			}
+			req.InstanceType = &instanceType
+			req.Placement = &cvm.Placement{
+				Zone: &zone,
+			}
+			req.VirtualPrivateCloud.SubnetId = &subnetId
+
+			err = s.launchInstance(ctx, state, req, instanceChargeType)
+			if err == nil {
+				state.Put("instance_type", instanceType)
+				state.Put("zone", zone)
+				state.Put("subnet_id", subnetId)
+				return nil
+			}
+
+			soldOut := isSoldOutError(err) || errors.Is(err, errInstanceLaunchFailed)
+			if instanceChargeType == "SPOTPAID" {
+				soldOut = isSpotUnavailableError(err)
+			}
+			if !soldOut {
+				return err
+			}
+			Message(state, fmt.Sprintf("Instance type %s is unavailable in zone %s(%s)", instanceType, zone, err), "")
+			s.discardInstance(ctx, state)
+		}
+	}
+
+	if err == nil {
+		err = fmt.Errorf("No subnet found in zones(%s) offering instance types(%s)",
+			strings.Join(zones, ","), strings.Join(s.InstanceTypes, ","))
+	}
+
+	return err
+}
+
+// launchInstance runs the instance described by req with the given charge
+// type, and waits for it to be running.
+func (s *stepRunInstance) launchInstance(ctx context.Context, state multistep.StateBag,
+	req *cvm.RunInstancesRequest, instanceChargeType string) error {
+	client := state.Get("cvm_client").(*cvm.Client)
+
+	req.InstanceChargeType = &instanceChargeType
+	req.InstanceMarketOptions = nil
+	if instanceChargeType == "SPOTPAID" {
+		req.InstanceMarketOptions = &cvm.InstanceMarketOptionsRequest{
+			MarketType: common.StringPtr("spot"),
+			SpotOptions: &cvm.SpotMarketOptions{
+				SpotInstanceType: &s.SpotInstanceType,
+			},
+		}
+		if s.SpotPrice != "" {
+			req.InstanceMarketOptions.SpotOptions.MaxPrice = &s.SpotPrice
+		}
+	}
+
+	// Every attempt needs its own idempotency token, otherwise the
+	// previous failed attempt would be returned.
+	s.attempts++
+	clientToken := s.InstanceName
+	if s.attempts > 1 {
+		clientToken = fmt.Sprintf("%s-%d", s.InstanceName, s.attempts)
+	}
+	req.ClientToken = &clientToken
+
+	var resp *cvm.RunInstancesResponse
+	err := Retry(ctx, func(ctx context.Context) error {
+		var e error
+		resp, e = s.runInstances(ctx, client, req)
+		return e
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(resp.Response.InstanceIdSet) != 1 {
+		return fmt.Errorf("No instance return")
+	}
+
+	s.instanceId = *resp.Response.InstanceIdSet[0]
+	Message(state, "Waiting for instance ready", "")
+
+	return WaitForInstance(ctx, client, s.instanceId, "RUNNING", 1800)
+}
+
+// buildDataDisks returns the data disks of the instance. The data disk
+// snapshots of the source image are always used, the user data disks
+// matching one of them by source_disk_index or disk_snapshot_id override its
+// settings, the others are added as new disks. The entry of disks every data
+// disk comes from is returned in the same order, nil for the source image data
+// disks not overridden.
+func buildDataDisks(sourceImage *cvm.Image, disks []tencentCloudDataDisk, defaultDiskType string) ([]*cvm.DataDisk, []*tencentCloudDataDisk, error) {
+	var snapshots []*cvm.Snapshot
+	for _, snapshot := range sourceImage.SnapshotSet {
+		if *snapshot.DiskUsage == "DATA_DISK" {
+			snapshots = append(snapshots, snapshot)
+		}
+	}
+
+	overrides := make([]*tencentCloudDataDisk, len(snapshots))
+	var extraDisks []*cvm.DataDisk
+	var extraSources []*tencentCloudDataDisk
+	for i := range disks {
+		disk := &disks[i]
+		index := -1
+		if disk.SourceDiskIndex != nil {
+			index = *disk.SourceDiskIndex
+			if index >= len(snapshots) {
+				return nil, nil, fmt.Errorf("data_disks[%d] source_disk_index(%d) is out of range, "+
+					"source image has %d data disks", i, index, len(snapshots))
+			}
+		} else if disk.SnapshotId != "" {
+			for j, snapshot := range snapshots {
+				if *snapshot.SnapshotId == disk.SnapshotId {
+					index = j
+				}
+			}
+		}
+
+		if index < 0 {
+			extraDisks = append(extraDisks, newDataDisk(disk))
+			extraSources = append(extraSources, disk)
+			continue
+		}
+		if overrides[index] != nil {
+			return nil, nil, fmt.Errorf("data_disks[%d] overrides source image data disk %d more than once", i, index)
+		}
+		overrides[index] = disk
+	}
+
+	dataDisks := make([]*cvm.DataDisk, 0, len(snapshots)+len(extraDisks))
+	sources := make([]*tencentCloudDataDisk, len(snapshots), len(snapshots)+len(extraSources))
+	for i, snapshot := range snapshots {
+		// The original disk type is unknown from the snapshot, so the
+		// system disk type is used unless overridden.
+		dataDisk := &cvm.DataDisk{
+			DiskType:           common.StringPtr(defaultDiskType),
+			DiskSize:           snapshot.DiskSize,
+			SnapshotId:         snapshot.SnapshotId,
+			DeleteWithInstance: common.BoolPtr(true),
+		}
+
+		if disk := overrides[i]; disk != nil {
+			if disk.DiskSize != 0 && disk.DiskSize < *snapshot.DiskSize {
+				return nil, nil, fmt.Errorf("data disk size of snapshot %s can only grow, from %dGB, not to %dGB",
+					*snapshot.SnapshotId, *snapshot.DiskSize, disk.DiskSize)
+			}
+			override := newDataDisk(disk)
+			if override.DiskType != nil {
+				dataDisk.DiskType = override.DiskType
+			}
+			if override.DiskSize != nil {
+				dataDisk.DiskSize = override.DiskSize
+			}
+			dataDisk.ThroughputPerformance = override.ThroughputPerformance
+			dataDisk.Encrypt = override.Encrypt
+			dataDisk.KmsKeyId = override.KmsKeyId
+			dataDisk.DeleteWithInstance = override.DeleteWithInstance
+			sources[i] = disk
+		}
+
+		dataDisks = append(dataDisks, dataDisk)
+	}
+
+	return append(dataDisks, extraDisks...), append(sources, extraSources...), nil
+}
+
+func newDataDisk(disk *tencentCloudDataDisk) *cvm.DataDisk {
+	dataDisk := cvm.DataDisk{
+		DeleteWithInstance: common.BoolPtr(disk.DeleteWithInstance == nil || *disk.DeleteWithInstance),
+	}
+	if disk.DiskType != "" {
+		dataDisk.DiskType = &disk.DiskType
+	}
+	if disk.DiskSize != 0 {
+		dataDisk.DiskSize = &disk.DiskSize
+	}
+	if disk.SnapshotId != "" {
+		dataDisk.SnapshotId = &disk.SnapshotId
+	}
+	if disk.ThroughputPerformance != 0 {
+		dataDisk.ThroughputPerformance = &disk.ThroughputPerformance
+	}
+	if disk.Encrypt {
+		dataDisk.Encrypt = common.BoolPtr(true)
+	}
+	if disk.KmsKeyId != "" {
+		dataDisk.KmsKeyId = &disk.KmsKeyId
+	}
+
+	return &dataDisk
+}
+
+// runInstancesRequest is a RunInstances request with the data disk burst
+// performance settings, which the sdk has no field for.
+type runInstancesRequest struct {
+	*cvm.RunInstancesRequest
+	DataDisks []*burstDataDisk `json:"DataDisks,omitempty"`
+}
+
+type burstDataDisk struct {
+	*cvm.DataDisk
+	BurstPerformance *bool `json:"BurstPerformance,omitempty"`
+}
+
+func (s *stepRunInstance) runInstances(ctx context.Context, client *cvm.Client, req *cvm.RunInstancesRequest) (*cvm.RunInstancesResponse, error) {
+	burst := false
+	for _, disk := range s.dataDiskSources {
+		if disk != nil && disk.BurstPerformance {
+			burst = true
+		}
+	}
+	if !burst {
+		return client.RunInstancesWithContext(ctx, req)
+	}
+
+	// The data disks of the wrapper replace the ones of the sdk request.
+	wrappedReq := &runInstancesRequest{RunInstancesRequest: req}
+	for i, dataDisk := range req.DataDisks {
+		disk := &burstDataDisk{DataDisk: dataDisk}
+		if source := s.dataDiskSources[i]; source != nil && source.BurstPerformance {
+			disk.BurstPerformance = common.BoolPtr(true)
+		}
+		wrappedReq.DataDisks = append(wrappedReq.DataDisks, disk)
+	}
+	wrappedReq.SetContext(ctx)
+
+	resp := cvm.NewRunInstancesResponse()
+	err := client.Send(wrappedReq, resp)
+	return resp, err
+}
+
+// discardInstance terminates the instance of a failed launch attempt.
+func (s *stepRunInstance) discardInstance(ctx context.Context, state multistep.StateBag) {
+	if s.instanceId == "" {
+		return
+	}
+
+	client := state.Get("cvm_client").(*cvm.Client)
+	if err := s.terminateInstance(ctx, client); err != nil {
+		Error(state, err, fmt.Sprintf("Failed to terminate instance(%s), please delete it manually", s.instanceId))
+	}
+	s.instanceId = ""
+}
+
+func (s *stepRunInstance) terminateInstance(ctx context.Context, client *cvm.Client) error {
+	req := cvm.NewTerminateInstancesRequest()
+	req.InstanceIds = []*string{&s.instanceId}
+
+	return Retry(ctx, func(ctx context.Context) error {
+		_, e := client.TerminateInstances(req)
+		return e
+	})
+}
+
 func (s *stepRunInstance) getUserData(state multistep.StateBag) (string, error) {
 	userData := s.UserData
 
@@ -228,13 +483,17 @@ func (s *stepRunInstance) Cleanup(state multistep.StateBag) {
 
 	SayClean(state, "instance")
 
-	req := cvm.NewTerminateInstancesRequest()
-	req.InstanceIds = []*string{&s.instanceId}
-	err := Retry(ctx, func(ctx context.Context) error {
-		_, e := client.TerminateInstances(req)
-		return e
-	})
+	err := s.terminateInstance(ctx, client)
 	if err != nil {
 		Error(state, err, fmt.Sprintf("Failed to terminate instance(%s), please delete it manually", s.instanceId))
 	}
 }
+
+// firstAddress returns the first of the ip addresses, or an empty string.
+func firstAddress(addresses []*string) string {
+	if len(addresses) == 0 || addresses[0] == nil {
+		return ""
+	}
+
+	return *addresses[0]
+}
@@ -0,0 +1,126 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package cvm
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common"
+	tcjson "github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common/json"
+	cvm "github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/cvm/v20170312"
+)
+
+func TestRunInstancesRequest_Marshal(t *testing.T) {
+	req := cvm.NewRunInstancesRequest()
+	req.ImageId = common.StringPtr("img-qwer1234")
+	req.SystemDisk = &cvm.SystemDisk{
+		DiskType: common.StringPtr("CLOUD_SSD"),
+		DiskSize: common.Int64Ptr(100),
+	}
+
+	req.DataDisks = []*cvm.DataDisk{
+		{DiskType: common.StringPtr("CLOUD_BSSD"), DiskSize: common.Int64Ptr(200)},
+	}
+
+	// The sdk marshals requests with its own json package, which leaves out
+	// the nil fields.
+	b, err := tcjson.Marshal(&runInstancesRequest{
+		RunInstancesRequest: req,
+		DataDisks: []*burstDataDisk{
+			{DataDisk: req.DataDisks[0], BurstPerformance: common.BoolPtr(true)},
+		},
+	})
+	if err != nil {
+		t.Fatalf("shouldn't have err: %v", err)
+	}
+	if strings.Contains(string(b), "null") {
+		t.Fatalf("nil fields should be left out: %s", b)
+	}
+
+	var body struct {
+		ImageId    string
+		SystemDisk map[string]interface{}
+		DataDisks  []map[string]interface{}
+	}
+	if err := json.Unmarshal(b, &body); err != nil {
+		t.Fatalf("shouldn't have err: %v", err)
+	}
+	if body.ImageId != "img-qwer1234" {
+		t.Fatalf("invalid request: %s", b)
+	}
+	if body.SystemDisk["DiskType"] != "CLOUD_SSD" || body.SystemDisk["DiskSize"] != float64(100) {
+		t.Fatalf("invalid system disk: %s", b)
+	}
+	if len(body.DataDisks) != 1 || body.DataDisks[0]["DiskType"] != "CLOUD_BSSD" ||
+		body.DataDisks[0]["BurstPerformance"] != true {
+		t.Fatalf("invalid data disks: %s", b)
+	}
+}
+
+func TestBuildDataDisks(t *testing.T) {
+	image := &cvm.Image{
+		SnapshotSet: []*cvm.Snapshot{
+			{SnapshotId: common.StringPtr("snap-system1"), DiskUsage: common.StringPtr("SYSTEM_DISK"), DiskSize: common.Int64Ptr(50)},
+			{SnapshotId: common.StringPtr("snap-data0001"), DiskUsage: common.StringPtr("DATA_DISK"), DiskSize: common.Int64Ptr(100)},
+			{SnapshotId: common.StringPtr("snap-data0002"), DiskUsage: common.StringPtr("DATA_DISK"), DiskSize: common.Int64Ptr(200)},
+		},
+	}
+	index := 1
+
+	dataDisks, sources, err := buildDataDisks(image, []tencentCloudDataDisk{
+		{SnapshotId: "snap-data0001", DiskType: "CLOUD_SSD", DiskSize: 150, Encrypt: true},
+		{SourceDiskIndex: &index, ThroughputPerformance: 100, MountTag: "data"},
+		{DiskType: "CLOUD_BASIC", DiskSize: 10, MountTag: "cache", DeleteWithInstance: common.BoolPtr(false)},
+	}, "CLOUD_PREMIUM")
+	if err != nil {
+		t.Fatalf("shouldn't have err: %v", err)
+	}
+	if len(dataDisks) != 3 {
+		t.Fatalf("invalid data disks count: %d", len(dataDisks))
+	}
+	if sources[0].SnapshotId != "snap-data0001" || sources[1].MountTag != "data" || sources[2].MountTag != "cache" {
+		t.Fatalf("invalid data disk sources: %v", sources)
+	}
+
+	first, second, extra := dataDisks[0], dataDisks[1], dataDisks[2]
+	if *first.SnapshotId != "snap-data0001" || *first.DiskType != "CLOUD_SSD" || *first.DiskSize != 150 ||
+		first.Encrypt == nil || !*first.Encrypt || !*first.DeleteWithInstance {
+		t.Fatalf("invalid first data disk: %s", dataDiskJson(first))
+	}
+	if *second.SnapshotId != "snap-data0002" || *second.DiskType != "CLOUD_PREMIUM" || *second.DiskSize != 200 ||
+		*second.ThroughputPerformance != 100 {
+		t.Fatalf("invalid second data disk: %s", dataDiskJson(second))
+	}
+	if extra.SnapshotId != nil || *extra.DiskType != "CLOUD_BASIC" || *extra.DiskSize != 10 ||
+		*extra.DeleteWithInstance {
+		t.Fatalf("invalid extra data disk: %s", dataDiskJson(extra))
+	}
+
+	if _, _, err := buildDataDisks(image, []tencentCloudDataDisk{
+		{SnapshotId: "snap-data0002", DiskSize: 100},
+	}, "CLOUD_PREMIUM"); err == nil {
+		t.Fatal("should have err: data disk can't shrink")
+	}
+
+	outOfRange := 2
+	if _, _, err := buildDataDisks(image, []tencentCloudDataDisk{
+		{SourceDiskIndex: &outOfRange},
+	}, "CLOUD_PREMIUM"); err == nil {
+		t.Fatal("should have err: source_disk_index out of range")
+	}
+
+	if _, _, err := buildDataDisks(image, []tencentCloudDataDisk{
+		{SourceDiskIndex: &index},
+		{SnapshotId: "snap-data0002"},
+	}, "CLOUD_PREMIUM"); err == nil {
+		t.Fatal("should have err: data disk overridden twice")
+	}
+}
+
+func dataDiskJson(disk *cvm.DataDisk) string {
+	b, _ := json.Marshal(disk)
+	return string(b)
+}
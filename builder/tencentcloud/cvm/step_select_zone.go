@@ -0,0 +1,109 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package cvm
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/packer-plugin-sdk/multistep"
+	"github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common"
+	cvm "github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/cvm/v20170312"
+)
+
+type stepSelectZone struct {
+	Zones                  []string
+	InstanceTypes          []string
+	InstanceChargeType     string
+	SpotFallbackToPostpaid bool
+}
+
+func (s *stepSelectZone) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
+	client := state.Get("cvm_client").(*cvm.Client)
+
+	Say(state, strings.Join(s.InstanceTypes, ","), "Trying to find zones offering instance types")
+
+	instanceChargeTypes := []string{s.InstanceChargeType}
+	if s.InstanceChargeType == "" {
+		instanceChargeTypes = []string{"POSTPAID_BY_HOUR"}
+	} else if s.InstanceChargeType == "SPOTPAID" && s.SpotFallbackToPostpaid {
+		instanceChargeTypes = append(instanceChargeTypes, "POSTPAID_BY_HOUR")
+	}
+
+	req := cvm.NewDescribeZoneInstanceConfigInfosRequest()
+	req.Filters = []*cvm.Filter{
+		{
+			Name:   common.StringPtr("instance-type"),
+			Values: common.StringPtrs(s.InstanceTypes),
+		},
+		{
+			Name:   common.StringPtr("instance-charge-type"),
+			Values: common.StringPtrs(instanceChargeTypes),
+		},
+	}
+	if len(s.Zones) > 0 {
+		req.Filters = append(req.Filters, &cvm.Filter{
+			Name:   common.StringPtr("zone"),
+			Values: common.StringPtrs(s.Zones),
+		})
+	}
+	var resp *cvm.DescribeZoneInstanceConfigInfosResponse
+	err := Retry(ctx, func(ctx context.Context) error {
+		var e error
+		resp, e = client.DescribeZoneInstanceConfigInfos(req)
+		return e
+	})
+	if err != nil {
+		return Halt(state, err, "Failed to get zone instance config info")
+	}
+
+	// The instance types offered by every zone, in the order they are
+	// configured in.
+	sold := make(map[string]map[string]struct{})
+	for _, item := range resp.Response.InstanceTypeQuotaSet {
+		if item.Status != nil && *item.Status == "SELL" {
+			if sold[*item.Zone] == nil {
+				sold[*item.Zone] = make(map[string]struct{})
+			}
+			sold[*item.Zone][*item.InstanceType] = struct{}{}
+		}
+	}
+	offered := make(map[string][]string, len(sold))
+	for zone, instanceTypes := range sold {
+		for _, instanceType := range s.InstanceTypes {
+			if _, ok := instanceTypes[instanceType]; ok {
+				offered[zone] = append(offered[zone], instanceType)
+			}
+		}
+	}
+
+	var zones []string
+	if len(s.Zones) > 0 {
+		// Keep the order zones are configured in.
+		for _, zone := range s.Zones {
+			if _, ok := offered[zone]; ok {
+				zones = append(zones, zone)
+			}
+		}
+	} else {
+		for zone := range offered {
+			zones = append(zones, zone)
+		}
+		sort.Strings(zones)
+	}
+
+	if len(zones) == 0 {
+		return Halt(state, fmt.Errorf("No zone offers instance types(%s)", strings.Join(s.InstanceTypes, ",")), "")
+	}
+
+	state.Put("zones", zones)
+	state.Put("zone_instance_types", offered)
+	Message(state, strings.Join(zones, ","), "Zones found")
+
+	return multistep.ActionContinue
+}
+
+func (s *stepSelectZone) Cleanup(multistep.StateBag) {}
@@ -0,0 +1,49 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package cvm
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/packer-plugin-sdk/multistep"
+	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
+)
+
+func TestStepSelectZone(t *testing.T) {
+	_, accessConfig := newFakeCvm(t, map[string]fakeCvmHandler{
+		"DescribeZoneInstanceConfigInfos": func(call fakeCvmCall) interface{} {
+			return map[string]interface{}{"InstanceTypeQuotaSet": []map[string]string{
+				{"Zone": "ap-guangzhou-4", "InstanceType": "SA2.MEDIUM2", "Status": "SELL"},
+				{"Zone": "ap-guangzhou-4", "InstanceType": "S5.MEDIUM2", "Status": "SOLD_OUT"},
+				{"Zone": "ap-guangzhou-3", "InstanceType": "S5.MEDIUM2", "Status": "SELL"},
+				{"Zone": "ap-guangzhou-6", "InstanceType": "S5.MEDIUM2", "Status": "SOLD_OUT"},
+			}}
+		},
+	})
+	client, err := NewCvmClient(accessConfig)
+	if err != nil {
+		t.Fatalf("shouldn't have err: %v", err)
+	}
+
+	state := new(multistep.BasicStateBag)
+	state.Put("ui", packersdk.TestUi(t))
+	state.Put("cvm_client", client)
+	step := &stepSelectZone{InstanceTypes: []string{"S5.MEDIUM2", "SA2.MEDIUM2"}}
+	if action := step.Run(context.Background(), state); action != multistep.ActionContinue {
+		t.Fatalf("shouldn't halt: %v", state.Get("error"))
+	}
+
+	if zones := state.Get("zones").([]string); !reflect.DeepEqual(zones, []string{"ap-guangzhou-3", "ap-guangzhou-4"}) {
+		t.Fatalf("unexpected zones: %v", zones)
+	}
+	expected := map[string][]string{
+		"ap-guangzhou-3": {"S5.MEDIUM2"},
+		"ap-guangzhou-4": {"SA2.MEDIUM2"},
+	}
+	if offered := state.Get("zone_instance_types").(map[string][]string); !reflect.DeepEqual(offered, expected) {
+		t.Fatalf("unexpected zone instance types: %v", offered)
+	}
+}
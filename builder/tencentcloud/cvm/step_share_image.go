@@ -6,6 +6,7 @@ package cvm
 import (
 	"context"
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/hashicorp/packer-plugin-sdk/multistep"
@@ -14,33 +15,71 @@ import (
 )
 
 type stepShareImage struct {
-	ShareAccounts []string
+	ShareAccounts       []string
+	RegionShareAccounts map[string][]string
+	AccessConfig        *TencentCloudAccessConfig
+	shared              map[string][]string
+}
+
+// NewStepShareImage returns the step sharing the images of every region in
+// state with the accounts, for builders creating the image their own way.
+func NewStepShareImage(accounts []string, regionAccounts map[string][]string,
+	accessConfig *TencentCloudAccessConfig) multistep.Step {
+	return &stepShareImage{
+		ShareAccounts:       accounts,
+		RegionShareAccounts: regionAccounts,
+		AccessConfig:        accessConfig,
+	}
+}
+
+// accounts returns the accounts the image of region is shared to.
+func (s *stepShareImage) accounts(region string) []string {
+	if accounts, ok := s.RegionShareAccounts[region]; ok {
+		return accounts
+	}
+
+	return s.ShareAccounts
+}
+
+// regionClient returns the cvm client of region.
+func (s *stepShareImage) regionClient(state multistep.StateBag, region string) (*cvm.Client, error) {
+	if region == s.AccessConfig.Region {
+		return state.Get("cvm_client").(*cvm.Client), nil
+	}
+
+	return newRegionCvmClient(s.AccessConfig, region)
 }
 
 func (s *stepShareImage) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
-	if len(s.ShareAccounts) == 0 {
+	tencentCloudImages := state.Get("tencentcloudimages").(map[string]string)
+
+	regions := make([]string, 0, len(tencentCloudImages))
+	for region := range tencentCloudImages {
+		if len(s.accounts(region)) > 0 {
+			regions = append(regions, region)
+		}
+	}
+	if len(regions) == 0 {
 		return multistep.ActionContinue
 	}
+	sort.Strings(regions)
 
-	client := state.Get("cvm_client").(*cvm.Client)
+	s.shared = make(map[string][]string)
+	for _, region := range regions {
+		imageId := tencentCloudImages[region]
+		accounts := s.accounts(region)
+		Say(state, fmt.Sprintf("%s(%s) to %s", imageId, region, strings.Join(accounts, ",")), "Trying to share image")
 
-	imageId := state.Get("image").(*cvm.Image).ImageId
-	Say(state, strings.Join(s.ShareAccounts, ","), "Trying to share image to")
+		client, err := s.regionClient(state, region)
+		if err != nil {
+			return Halt(state, err, "Failed to init client")
+		}
 
-	req := cvm.NewModifyImageSharePermissionRequest()
-	req.ImageId = imageId
-	req.Permission = common.StringPtr("SHARE")
-	accounts := make([]*string, 0, len(s.ShareAccounts))
-	for _, account := range s.ShareAccounts {
-		accounts = append(accounts, common.StringPtr(account))
-	}
-	req.AccountIds = accounts
-	err := Retry(ctx, func(ctx context.Context) error {
-		_, e := client.ModifyImageSharePermission(req)
-		return e
-	})
-	if err != nil {
-		return Halt(state, err, "Failed to share image")
+		err = shareImage(ctx, client, imageId, "SHARE", accounts)
+		if err != nil {
+			return Halt(state, err, "Failed to share image")
+		}
+		s.shared[region] = accounts
 	}
 
 	Message(state, "Image shared", "")
@@ -49,6 +88,10 @@ func (s *stepShareImage) Run(ctx context.Context, state multistep.StateBag) mult
 }
 
 func (s *stepShareImage) Cleanup(state multistep.StateBag) {
+	if len(s.shared) == 0 {
+		return
+	}
+
 	_, cancelled := state.GetOk(multistep.StateCancelled)
 	_, halted := state.GetOk(multistep.StateHalted)
 	if !cancelled && !halted {
@@ -56,25 +99,37 @@ func (s *stepShareImage) Cleanup(state multistep.StateBag) {
 	}
 
 	ctx := context.TODO()
-	client := state.Get("cvm_client").(*cvm.Client)
+	tencentCloudImages := state.Get("tencentcloudimages").(map[string]string)
 
-	imageId := state.Get("image").(*cvm.Image).ImageId
 	SayClean(state, "image share")
 
-	req := cvm.NewModifyImageSharePermissionRequest()
-	req.ImageId = imageId
-	req.Permission = common.StringPtr("CANCEL")
-	accounts := make([]*string, 0, len(s.ShareAccounts))
-	for _, account := range s.ShareAccounts {
-		account := account
-		accounts = append(accounts, &account)
+	for region, accounts := range s.shared {
+		imageId := tencentCloudImages[region]
+
+		client, err := s.regionClient(state, region)
+		if err != nil {
+			Error(state, err, fmt.Sprintf("Failed to init client of region(%s), please cancel share image(%s) manually",
+				region, imageId))
+			continue
+		}
+
+		err = shareImage(ctx, client, imageId, "CANCEL", accounts)
+		if err != nil {
+			Error(state, err, fmt.Sprintf("Failed to cancel share image(%s), please delete it manually", imageId))
+		}
 	}
-	req.AccountIds = accounts
-	err := Retry(ctx, func(ctx context.Context) error {
+}
+
+// shareImage shares the image with the accounts, or cancels it depending on
+// permission.
+func shareImage(ctx context.Context, client *cvm.Client, imageId string, permission string, accounts []string) error {
+	req := cvm.NewModifyImageSharePermissionRequest()
+	req.ImageId = &imageId
+	req.Permission = &permission
+	req.AccountIds = common.StringPtrs(accounts)
+
+	return Retry(ctx, func(ctx context.Context) error {
 		_, e := client.ModifyImageSharePermission(req)
 		return e
 	})
-	if err != nil {
-		Error(state, err, fmt.Sprintf("Failed to cancel share image(%s), please delete it manually", *imageId))
-	}
 }
@@ -0,0 +1,67 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package cvm
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/packer-plugin-sdk/multistep"
+	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
+)
+
+func TestStepShareImage(t *testing.T) {
+	fake, accessConfig := newFakeCvm(t, map[string]fakeCvmHandler{
+		"ModifyImageSharePermission": func(fakeCvmCall) interface{} { return nil },
+	})
+	client, err := NewCvmClient(accessConfig)
+	if err != nil {
+		t.Fatalf("shouldn't have err: %v", err)
+	}
+
+	state := new(multistep.BasicStateBag)
+	state.Put("ui", packersdk.TestUi(t))
+	state.Put("cvm_client", client)
+	state.Put("tencentcloudimages", map[string]string{
+		"ap-guangzhou": "img-gz",
+		"ap-shanghai":  "img-sh",
+		"ap-beijing":   "img-bj",
+	})
+
+	step := NewStepShareImage([]string{"100000000001"}, map[string][]string{
+		"ap-shanghai": {"100000000002", "100000000003"},
+		"ap-beijing":  {},
+	}, accessConfig)
+	if action := step.Run(context.Background(), state); action != multistep.ActionContinue {
+		t.Fatal("should continue")
+	}
+
+	state.Put(multistep.StateHalted, true)
+	step.Cleanup(state)
+
+	var changes []string
+	for _, call := range fake.callsOf("ModifyImageSharePermission") {
+		var req struct {
+			ImageId    string
+			Permission string
+			AccountIds []string
+		}
+		call.decode(&req)
+		changes = append(changes, fmt.Sprintf("%s:%s:%s:%s", call.Region,
+			req.ImageId, req.Permission, strings.Join(req.AccountIds, "+")))
+	}
+	sort.Strings(changes)
+	expected := []string{
+		"ap-guangzhou:img-gz:CANCEL:100000000001",
+		"ap-guangzhou:img-gz:SHARE:100000000001",
+		"ap-shanghai:img-sh:CANCEL:100000000002+100000000003",
+		"ap-shanghai:img-sh:SHARE:100000000002+100000000003",
+	}
+	if strings.Join(changes, ",") != strings.Join(expected, ",") {
+		t.Fatalf("unexpected share changes: %v", changes)
+	}
+}
@@ -0,0 +1,66 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package cvm
+
+import (
+	"context"
+
+	"github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common"
+	tchttp "github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common/http"
+	"github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common/profile"
+)
+
+const tagAPIVersion = "2018-08-13"
+
+// TagClient is a client of the tag api, covering the few actions the
+// builders need.
+type TagClient struct {
+	common.Client
+}
+
+func newTagClient(credential common.CredentialIface, region string, cpf *profile.ClientProfile) *TagClient {
+	client := &TagClient{}
+	client.Init(region).
+		WithCredential(credential).
+		WithProfile(cpf)
+	return client
+}
+
+type tagTag struct {
+	TagKey   *string `json:"TagKey,omitempty"`
+	TagValue *string `json:"TagValue,omitempty"`
+}
+
+type tagModifyResourceTagsRequest struct {
+	*tchttp.BaseRequest
+
+	// The six segment description of the resource,
+	// qcs::<service>:<region>:uin/<uin>:<type>/<id>.
+	Resource    *string   `json:"Resource,omitempty"`
+	ReplaceTags []*tagTag `json:"ReplaceTags,omitempty"`
+}
+
+func newTagModifyResourceTagsRequest() *tagModifyResourceTagsRequest {
+	req := &tagModifyResourceTagsRequest{BaseRequest: &tchttp.BaseRequest{}}
+	req.Init().WithApiInfo("tag", tagAPIVersion, "ModifyResourceTags")
+	return req
+}
+
+// modifyResourceTags adds the tags to the resource, replacing the values of
+// the keys it already has.
+func (c *TagClient) modifyResourceTags(ctx context.Context, resource string, tags map[string]string) error {
+	req := newTagModifyResourceTagsRequest()
+	req.Resource = &resource
+	for k, v := range tags {
+		req.ReplaceTags = append(req.ReplaceTags, &tagTag{
+			TagKey:   common.StringPtr(k),
+			TagValue: common.StringPtr(v),
+		})
+	}
+
+	return Retry(ctx, func(ctx context.Context) error {
+		req.SetContext(ctx)
+		return c.Send(req, &tchttp.BaseResponse{})
+	})
+}
@@ -0,0 +1,159 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package cvm
+
+import (
+	"context"
+
+	"github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common"
+	tchttp "github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common/http"
+	"github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common/profile"
+)
+
+const tatAPIVersion = "2020-10-28"
+
+// TatClient is a client of the TencentCloud Automation Tools api, covering
+// the few actions the tat communicator needs.
+type TatClient struct {
+	common.Client
+}
+
+func newTatClient(credential common.CredentialIface, region string, cpf *profile.ClientProfile) *TatClient {
+	client := &TatClient{}
+	client.Init(region).
+		WithCredential(credential).
+		WithProfile(cpf)
+	return client
+}
+
+type tatFilter struct {
+	Name   *string   `json:"Name,omitempty"`
+	Values []*string `json:"Values,omitempty"`
+}
+
+type tatRunCommandRequest struct {
+	*tchttp.BaseRequest
+
+	// Base64 encoded content of the command.
+	Content     *string   `json:"Content,omitempty"`
+	InstanceIds []*string `json:"InstanceIds,omitempty"`
+	CommandName *string   `json:"CommandName,omitempty"`
+	// SHELL, POWERSHELL or BAT.
+	CommandType      *string `json:"CommandType,omitempty"`
+	WorkingDirectory *string `json:"WorkingDirectory,omitempty"`
+	// Timeout of the command in seconds.
+	Timeout     *uint64 `json:"Timeout,omitempty"`
+	SaveCommand *bool   `json:"SaveCommand,omitempty"`
+	Username    *string `json:"Username,omitempty"`
+}
+
+type tatRunCommandResponse struct {
+	*tchttp.BaseResponse
+	Response *struct {
+		CommandId    *string `json:"CommandId,omitempty"`
+		InvocationId *string `json:"InvocationId,omitempty"`
+		RequestId    *string `json:"RequestId,omitempty"`
+	} `json:"Response"`
+}
+
+func newTatRunCommandRequest() *tatRunCommandRequest {
+	req := &tatRunCommandRequest{BaseRequest: &tchttp.BaseRequest{}}
+	req.Init().WithApiInfo("tat", tatAPIVersion, "RunCommand")
+	return req
+}
+
+func (c *TatClient) runCommand(ctx context.Context, req *tatRunCommandRequest) (*tatRunCommandResponse, error) {
+	req.SetContext(ctx)
+	resp := &tatRunCommandResponse{BaseResponse: &tchttp.BaseResponse{}}
+	err := c.Send(req, resp)
+	return resp, err
+}
+
+type tatTaskResult struct {
+	ExitCode *int64 `json:"ExitCode,omitempty"`
+	// Base64 encoded output of the command, truncated to 24KB.
+	Output *string `json:"Output,omitempty"`
+	// Bytes of output dropped by the truncation.
+	Dropped *uint64 `json:"Dropped,omitempty"`
+}
+
+type tatInvocationTask struct {
+	InvocationId     *string        `json:"InvocationId,omitempty"`
+	InvocationTaskId *string        `json:"InvocationTaskId,omitempty"`
+	InstanceId       *string        `json:"InstanceId,omitempty"`
+	TaskStatus       *string        `json:"TaskStatus,omitempty"`
+	TaskResult       *tatTaskResult `json:"TaskResult,omitempty"`
+	ErrorInfo        *string        `json:"ErrorInfo,omitempty"`
+}
+
+type tatDescribeInvocationTasksRequest struct {
+	*tchttp.BaseRequest
+
+	InvocationTaskIds []*string    `json:"InvocationTaskIds,omitempty"`
+	Filters           []*tatFilter `json:"Filters,omitempty"`
+	Limit             *uint64      `json:"Limit,omitempty"`
+	Offset            *uint64      `json:"Offset,omitempty"`
+	HideOutput        *bool        `json:"HideOutput,omitempty"`
+}
+
+type tatDescribeInvocationTasksResponse struct {
+	*tchttp.BaseResponse
+	Response *struct {
+		TotalCount        *uint64              `json:"TotalCount,omitempty"`
+		InvocationTaskSet []*tatInvocationTask `json:"InvocationTaskSet,omitempty"`
+		RequestId         *string              `json:"RequestId,omitempty"`
+	} `json:"Response"`
+}
+
+func newTatDescribeInvocationTasksRequest() *tatDescribeInvocationTasksRequest {
+	req := &tatDescribeInvocationTasksRequest{BaseRequest: &tchttp.BaseRequest{}}
+	req.Init().WithApiInfo("tat", tatAPIVersion, "DescribeInvocationTasks")
+	return req
+}
+
+func (c *TatClient) describeInvocationTasks(ctx context.Context,
+	req *tatDescribeInvocationTasksRequest) (*tatDescribeInvocationTasksResponse, error) {
+	req.SetContext(ctx)
+	resp := &tatDescribeInvocationTasksResponse{BaseResponse: &tchttp.BaseResponse{}}
+	err := c.Send(req, resp)
+	return resp, err
+}
+
+type tatAutomationAgentInfo struct {
+	InstanceId *string `json:"InstanceId,omitempty"`
+	Version    *string `json:"Version,omitempty"`
+	// Online or Offline.
+	AgentStatus *string `json:"AgentStatus,omitempty"`
+	// Linux or Windows.
+	Environment *string `json:"Environment,omitempty"`
+}
+
+type tatDescribeAutomationAgentStatusRequest struct {
+	*tchttp.BaseRequest
+
+	InstanceIds []*string `json:"InstanceIds,omitempty"`
+}
+
+type tatDescribeAutomationAgentStatusResponse struct {
+	*tchttp.BaseResponse
+	Response *struct {
+		AutomationAgentSet []*tatAutomationAgentInfo `json:"AutomationAgentSet,omitempty"`
+		TotalCount         *uint64                   `json:"TotalCount,omitempty"`
+		RequestId          *string                   `json:"RequestId,omitempty"`
+	} `json:"Response"`
+}
+
+func newTatDescribeAutomationAgentStatusRequest() *tatDescribeAutomationAgentStatusRequest {
+	req := &tatDescribeAutomationAgentStatusRequest{BaseRequest: &tchttp.BaseRequest{}}
+	req.Init().WithApiInfo("tat", tatAPIVersion, "DescribeAutomationAgentStatus")
+	return req
+}
+
+func (c *TatClient) describeAutomationAgentStatus(ctx context.Context,
+	req *tatDescribeAutomationAgentStatusRequest) (*tatDescribeAutomationAgentStatusResponse, error) {
+	req.SetContext(ctx)
+	resp := &tatDescribeAutomationAgentStatusResponse{BaseResponse: &tchttp.BaseResponse{}}
+	err := c.Send(req, resp)
+	return resp, err
+}
@@ -0,0 +1,223 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+//go:generate packer-sdc struct-markdown
+//go:generate packer-sdc mapstructure-to-hcl2 -type DatasourceOutput,Config,ImageSnapshot
+
+package image
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	"github.com/hashicorp/hcl/v2/hcldec"
+	"github.com/hashicorp/packer-plugin-sdk/common"
+	"github.com/hashicorp/packer-plugin-sdk/hcl2helper"
+	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
+	"github.com/hashicorp/packer-plugin-sdk/template/config"
+	tccommon "github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common"
+	cvm "github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/cvm/v20170312"
+	"github.com/zclconf/go-cty/cty"
+
+	builder "github.com/hashicorp/packer-plugin-tencentcloud/builder/tencentcloud/cvm"
+)
+
+type Config struct {
+	common.PackerConfig              `mapstructure:",squash"`
+	builder.TencentCloudAccessConfig `mapstructure:",squash"`
+	// Filters used to select an image. Any filter described in the docs for
+	// [DescribeImages](https://intl.cloud.tencent.com/document/api/213/33272)
+	// is valid, for example `image-name` or `image-id`.
+	Filters map[string]string `mapstructure:"filters" required:"false"`
+	// Type of the image, values can be `PRIVATE_IMAGE`, `PUBLIC_IMAGE`
+	// or `SHARED_IMAGE`.
+	ImageType string `mapstructure:"image_type" required:"false"`
+	// Platform of the image, such as `TencentOS`, `CentOS` or `Ubuntu`.
+	Platform string `mapstructure:"platform" required:"false"`
+	// A regex matched against the operating system name of the image,
+	// such as `^TencentOS Server 3`.
+	OsName string `mapstructure:"os_name" required:"false"`
+	// Key/value pair tags the image must carry.
+	Tags map[string]string `mapstructure:"tags" required:"false"`
+	// Account ids of the image creators. Images created by other accounts
+	// are filtered out.
+	Owners []string `mapstructure:"owners" required:"false"`
+	// Selects the newest created image when multiple results are returned.
+	// Note that the query fails when several images match and this is not set.
+	MostRecent bool `mapstructure:"most_recent" required:"false"`
+}
+
+type Datasource struct {
+	config Config
+}
+
+func (d *Datasource) ConfigSpec() hcldec.ObjectSpec {
+	return d.config.FlatMapstructure().HCL2Spec()
+}
+
+func (d *Datasource) Configure(raws ...interface{}) error {
+	err := config.Decode(&d.config, nil, raws...)
+	if err != nil {
+		return err
+	}
+
+	var errs *packersdk.MultiError
+	errs = packersdk.MultiErrorAppend(errs, d.config.TencentCloudAccessConfig.Prepare(nil)...)
+
+	if d.config.ImageType != "" && !builder.CheckImageType(d.config.ImageType) {
+		errs = packersdk.MultiErrorAppend(errs,
+			fmt.Errorf("specified image_type(%s) is invalid", d.config.ImageType))
+	}
+
+	if d.config.OsName != "" {
+		if _, err := regexp.Compile(d.config.OsName); err != nil {
+			errs = packersdk.MultiErrorAppend(errs, fmt.Errorf("os_name is not a valid regex: %s", err))
+		}
+	}
+
+	if len(d.config.Filters) == 0 && d.config.ImageType == "" && d.config.Platform == "" &&
+		d.config.OsName == "" && len(d.config.Tags) == 0 && len(d.config.Owners) == 0 {
+		errs = packersdk.MultiErrorAppend(errs,
+			fmt.Errorf("at least one of filters, image_type, platform, os_name, tags or owners must be specified"))
+	}
+
+	if errs != nil && len(errs.Errors) > 0 {
+		return errs
+	}
+
+	packersdk.LogSecretFilter.Set(d.config.SecretId, d.config.SecretKey)
+
+	return nil
+}
+
+type ImageSnapshot struct {
+	// The id of the snapshot.
+	SnapshotId string `mapstructure:"snapshot_id"`
+	// The usage of the disk the snapshot was taken from, `SYSTEM_DISK` or `DATA_DISK`.
+	DiskUsage string `mapstructure:"disk_usage"`
+	// The size of the disk the snapshot was taken from, in GB.
+	DiskSize int64 `mapstructure:"disk_size"`
+}
+
+type DatasourceOutput struct {
+	// The id of the image.
+	ID string `mapstructure:"id"`
+	// The name of the image.
+	Name string `mapstructure:"name"`
+	// The platform of the image.
+	Platform string `mapstructure:"platform"`
+	// The operating system name of the image.
+	OsName string `mapstructure:"os_name"`
+	// The date of creation of the image.
+	CreatedTime string `mapstructure:"created_time"`
+	// The snapshots the image is made of.
+	SnapshotSet []ImageSnapshot `mapstructure:"snapshot_set"`
+}
+
+func (d *Datasource) OutputSpec() hcldec.ObjectSpec {
+	return (&DatasourceOutput{}).FlatMapstructure().HCL2Spec()
+}
+
+func (d *Datasource) Execute() (cty.Value, error) {
+	ctx := context.TODO()
+
+	client, err := builder.NewCvmClient(&d.config.TencentCloudAccessConfig)
+	if err != nil {
+		return cty.NullVal(cty.EmptyObject), err
+	}
+
+	images, err := builder.GetImages(ctx, client, d.describeImagesRequest())
+	if err != nil {
+		return cty.NullVal(cty.EmptyObject), err
+	}
+
+	images = d.filterImages(images)
+	if len(images) == 0 {
+		return cty.NullVal(cty.EmptyObject), fmt.Errorf("No image found using the specified filters")
+	}
+
+	if len(images) > 1 && !d.config.MostRecent {
+		return cty.NullVal(cty.EmptyObject), fmt.Errorf("Your query returned more than one result. " +
+			"Please try a more specific search, or set most_recent to true.")
+	}
+
+	image := builder.MostRecentImage(images)
+	output := DatasourceOutput{
+		ID:          *image.ImageId,
+		Name:        *image.ImageName,
+		Platform:    stringValue(image.Platform),
+		OsName:      stringValue(image.OsName),
+		CreatedTime: stringValue(image.CreatedTime),
+	}
+	for _, snapshot := range image.SnapshotSet {
+		output.SnapshotSet = append(output.SnapshotSet, ImageSnapshot{
+			SnapshotId: stringValue(snapshot.SnapshotId),
+			DiskUsage:  stringValue(snapshot.DiskUsage),
+			DiskSize:   int64Value(snapshot.DiskSize),
+		})
+	}
+
+	return hcl2helper.HCL2ValueFromConfig(output, d.OutputSpec()), nil
+}
+
+func (d *Datasource) describeImagesRequest() *cvm.DescribeImagesRequest {
+	req := cvm.NewDescribeImagesRequest()
+
+	var filters []*cvm.Filter
+	for k, v := range d.config.Filters {
+		filters = append(filters, &cvm.Filter{
+			Name:   tccommon.StringPtr(k),
+			Values: []*string{tccommon.StringPtr(v)},
+		})
+	}
+	filters = append(filters, builder.ImageFilters(d.config.ImageType, d.config.Platform, d.config.Tags)...)
+	if len(filters) > 0 {
+		req.Filters = filters
+	}
+
+	return req
+}
+
+// filterImages applies the filters DescribeImages has no server side
+// support for.
+func (d *Datasource) filterImages(images []*cvm.Image) []*cvm.Image {
+	var osNameRegex *regexp.Regexp
+	if d.config.OsName != "" {
+		osNameRegex = regexp.MustCompile(d.config.OsName)
+	}
+
+	owners := make(map[string]struct{}, len(d.config.Owners))
+	for _, owner := range d.config.Owners {
+		owners[owner] = struct{}{}
+	}
+
+	var result []*cvm.Image
+	for _, image := range images {
+		if osNameRegex != nil && !osNameRegex.MatchString(stringValue(image.OsName)) {
+			continue
+		}
+		if len(owners) > 0 {
+			if _, ok := owners[stringValue(image.ImageCreator)]; !ok {
+				continue
+			}
+		}
+		result = append(result, image)
+	}
+
+	return result
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func int64Value(i *int64) int64 {
+	if i == nil {
+		return 0
+	}
+	return *i
+}
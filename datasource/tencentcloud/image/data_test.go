@@ -0,0 +1,111 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package image
+
+import (
+	"testing"
+
+	cvm "github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/cvm/v20170312"
+
+	builder "github.com/hashicorp/packer-plugin-tencentcloud/builder/tencentcloud/cvm"
+)
+
+func testConfig() map[string]interface{} {
+	return map[string]interface{}{
+		"secret_id":  "secret-id",
+		"secret_key": "secret-key",
+		"region":     "ap-guangzhou",
+	}
+}
+
+func TestDatasource_Configure(t *testing.T) {
+	d := &Datasource{}
+	if err := d.Configure(testConfig()); err == nil {
+		t.Fatal("should have error: no filter set")
+	}
+
+	raw := testConfig()
+	raw["image_type"] = "PUBLIC_IMAGE"
+	raw["platform"] = "TencentOS"
+	raw["most_recent"] = true
+	d = &Datasource{}
+	if err := d.Configure(raw); err != nil {
+		t.Fatalf("shouldn't have error: %v", err)
+	}
+
+	raw["image_type"] = "UNKNOWN_IMAGE"
+	d = &Datasource{}
+	if err := d.Configure(raw); err == nil {
+		t.Fatal("should have error: invalid image_type")
+	}
+
+	raw = testConfig()
+	raw["os_name"] = "TencentOS Server ("
+	d = &Datasource{}
+	if err := d.Configure(raw); err == nil {
+		t.Fatal("should have error: invalid os_name regex")
+	}
+}
+
+func TestDatasource_DescribeImagesRequest(t *testing.T) {
+	raw := testConfig()
+	raw["image_type"] = "PRIVATE_IMAGE"
+	raw["tags"] = map[string]string{"team": "infra"}
+	raw["filters"] = map[string]string{"image-name": "base"}
+	d := &Datasource{}
+	if err := d.Configure(raw); err != nil {
+		t.Fatalf("shouldn't have error: %v", err)
+	}
+
+	filters := make(map[string]string)
+	for _, filter := range d.describeImagesRequest().Filters {
+		filters[*filter.Name] = *filter.Values[0]
+	}
+
+	expected := map[string]string{
+		"image-type": "PRIVATE_IMAGE",
+		"tag:team":   "infra",
+		"image-name": "base",
+	}
+	if len(filters) != len(expected) {
+		t.Fatalf("unexpected filters: %v", filters)
+	}
+	for k, v := range expected {
+		if filters[k] != v {
+			t.Fatalf("filter %s should be %s, got %s", k, v, filters[k])
+		}
+	}
+}
+
+func TestDatasource_FilterImages(t *testing.T) {
+	raw := testConfig()
+	raw["os_name"] = "^TencentOS"
+	raw["owners"] = []string{"100000000001"}
+	d := &Datasource{}
+	if err := d.Configure(raw); err != nil {
+		t.Fatalf("shouldn't have error: %v", err)
+	}
+
+	image := func(id, osName, creator, createdTime string) *cvm.Image {
+		return &cvm.Image{
+			ImageId:      &id,
+			OsName:       &osName,
+			ImageCreator: &creator,
+			CreatedTime:  &createdTime,
+		}
+	}
+	images := d.filterImages([]*cvm.Image{
+		image("img-00000001", "TencentOS Server 3.1", "100000000001", "2023-01-01T00:00:00+00:00"),
+		image("img-00000002", "TencentOS Server 3.1", "100000000001", "2024-01-01T00:00:00+00:00"),
+		image("img-00000003", "CentOS 7.9 64bit", "100000000001", "2025-01-01T00:00:00+00:00"),
+		image("img-00000004", "TencentOS Server 3.1", "100000000002", "2025-01-01T00:00:00+00:00"),
+	})
+	if len(images) != 2 {
+		t.Fatalf("should match 2 images, got %d", len(images))
+	}
+
+	if id := *builder.MostRecentImage(images).ImageId; id != "img-00000002" {
+		t.Fatalf("most recent image should be img-00000002, got %s", id)
+	}
+}
@@ -9,13 +9,21 @@ import (
 
 	"github.com/hashicorp/packer-plugin-sdk/plugin"
 
+	"github.com/hashicorp/packer-plugin-tencentcloud/builder/tencentcloud/chroot"
 	"github.com/hashicorp/packer-plugin-tencentcloud/builder/tencentcloud/cvm"
+	"github.com/hashicorp/packer-plugin-tencentcloud/datasource/tencentcloud/image"
+	tencentcloudexport "github.com/hashicorp/packer-plugin-tencentcloud/post-processor/tencentcloud-export"
+	tencentcloudimport "github.com/hashicorp/packer-plugin-tencentcloud/post-processor/tencentcloud-import"
 	"github.com/hashicorp/packer-plugin-tencentcloud/version"
 )
 
 func main() {
 	pps := plugin.NewSet()
 	pps.RegisterBuilder("cvm", new(cvm.Builder))
+	pps.RegisterBuilder("chroot", new(chroot.Builder))
+	pps.RegisterDatasource("image", new(image.Datasource))
+	pps.RegisterPostProcessor("import", new(tencentcloudimport.PostProcessor))
+	pps.RegisterPostProcessor("export", new(tencentcloudexport.PostProcessor))
 	pps.SetVersion(version.PluginVersion)
 	err := pps.Run()
 	if err != nil {
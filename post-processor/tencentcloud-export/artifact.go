@@ -0,0 +1,73 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package tencentcloudexport
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
+	"github.com/tencentyun/cos-go-sdk-v5"
+)
+
+// Artifact is the objects an image was exported as, and their local copies
+// if downloaded.
+type Artifact struct {
+	ImageId   string
+	CosBucket string
+	CosKeys   []string
+	Client    *cos.Client
+
+	files []string
+}
+
+func (a *Artifact) BuilderId() string {
+	return BuilderId
+}
+
+func (a *Artifact) Files() []string {
+	return a.files
+}
+
+func (a *Artifact) Id() string {
+	return strings.Join(a.CosKeys, ",")
+}
+
+func (a *Artifact) String() string {
+	return fmt.Sprintf("Tencentcloud image(%s) was exported to cos bucket %s as:\n%s",
+		a.ImageId, a.CosBucket, strings.Join(a.CosKeys, "\n"))
+}
+
+func (a *Artifact) State(name string) interface{} {
+	return nil
+}
+
+func (a *Artifact) Destroy() error {
+	ctx := context.TODO()
+	errors := make([]error, 0)
+
+	for _, file := range a.files {
+		if err := os.Remove(file); err != nil && !os.IsNotExist(err) {
+			errors = append(errors, err)
+		}
+	}
+
+	for _, key := range a.CosKeys {
+		log.Printf("Delete object(%s) from cos bucket(%s)", key, a.CosBucket)
+		if _, err := a.Client.Object.Delete(ctx, key); err != nil {
+			errors = append(errors, err)
+		}
+	}
+
+	if len(errors) == 1 {
+		return errors[0]
+	} else if len(errors) > 1 {
+		return &packersdk.MultiError{Errors: errors}
+	} else {
+		return nil
+	}
+}
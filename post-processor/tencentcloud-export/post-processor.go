@@ -0,0 +1,292 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+//go:generate packer-sdc struct-markdown
+//go:generate packer-sdc mapstructure-to-hcl2 -type Config
+
+// Package tencentcloudexport exports TencentCloud images to a cos bucket,
+// optionally downloading them.
+package tencentcloudexport
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/hcl/v2/hcldec"
+	"github.com/hashicorp/packer-plugin-sdk/common"
+	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
+	"github.com/hashicorp/packer-plugin-sdk/template/config"
+	"github.com/hashicorp/packer-plugin-sdk/template/interpolate"
+	cvm "github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/cvm/v20170312"
+	"github.com/tencentyun/cos-go-sdk-v5"
+
+	"github.com/hashicorp/packer-plugin-tencentcloud/builder/tencentcloud/chroot"
+	builder "github.com/hashicorp/packer-plugin-tencentcloud/builder/tencentcloud/cvm"
+	tencentcloudimport "github.com/hashicorp/packer-plugin-tencentcloud/post-processor/tencentcloud-import"
+)
+
+const BuilderId = "packer.post-processor.tencentcloud-export"
+
+// ValidFormats are the disk image formats ExportImages accepts.
+var ValidFormats = []string{"qcow2", "vhd", "vmdk", "raw"}
+
+// acceptedBuilderIds are the artifacts holding tencentcloud images.
+var acceptedBuilderIds = []string{
+	builder.BuilderId,
+	chroot.BuilderId,
+	tencentcloudimport.BuilderId,
+}
+
+type Config struct {
+	common.PackerConfig              `mapstructure:",squash"`
+	builder.TencentCloudAccessConfig `mapstructure:",squash"`
+
+	// The name of the cos bucket the image is exported to, in the form
+	// `<name>-<appid>`. It must be in the same region as the image, which
+	// is the `region` one of the artifact.
+	CosBucket string `mapstructure:"cos_bucket" required:"true"`
+	// The url of the cos bucket, default is
+	// `https://<cos_bucket>.cos.<region>.myqcloud.com`.
+	CosEndpoint string `mapstructure:"cos_endpoint" required:"false"`
+	// The prefix of the exported objects keys, default is
+	// `packer-export-<image_id>`.
+	CosKeyPrefix string `mapstructure:"cos_key_prefix" required:"false"`
+	// The format of the exported disk images, one of `qcow2`, `vhd`, `vmdk`
+	// or `raw`. Default is `qcow2`.
+	Format string `mapstructure:"format" required:"false"`
+	// Only export the system disk of the image, not its data disks.
+	OnlyExportRootDisk bool `mapstructure:"only_export_root_disk" required:"false"`
+	// The role cvm assumes to write to the cos bucket, default is
+	// `CVM_QcsRole`.
+	RoleName string `mapstructure:"role_name" required:"false"`
+	// How long to wait for the export to finish, default is `1h`.
+	ExportTimeout time.Duration `mapstructure:"export_timeout" required:"false"`
+	// The local directory the exported objects are downloaded to, they are
+	// not downloaded if not set. The downloaded files are the files of the
+	// artifact.
+	OutputDirectory string `mapstructure:"output_directory" required:"false"`
+
+	ctx interpolate.Context
+}
+
+type PostProcessor struct {
+	config Config
+}
+
+func (p *PostProcessor) ConfigSpec() hcldec.ObjectSpec { return p.config.FlatMapstructure().HCL2Spec() }
+
+func (p *PostProcessor) Configure(raws ...interface{}) error {
+	err := config.Decode(&p.config, &config.DecodeOpts{
+		PluginType:         BuilderId,
+		Interpolate:        true,
+		InterpolateContext: &p.config.ctx,
+	}, raws...)
+	if err != nil {
+		return err
+	}
+
+	if p.config.Format == "" {
+		p.config.Format = "qcow2"
+	}
+
+	if p.config.ExportTimeout == 0 {
+		p.config.ExportTimeout = time.Hour
+	}
+
+	// Accumulate any errors
+	var errs *packersdk.MultiError
+	errs = packersdk.MultiErrorAppend(errs, p.config.TencentCloudAccessConfig.Prepare(&p.config.ctx)...)
+
+	if p.config.CosBucket == "" {
+		errs = packersdk.MultiErrorAppend(errs, errors.New("cos_bucket must be specified"))
+	}
+
+	validFormat := false
+	for _, valid := range ValidFormats {
+		if p.config.Format == valid {
+			validFormat = true
+		}
+	}
+	if !validFormat {
+		errs = packersdk.MultiErrorAppend(errs, fmt.Errorf("specified format(%s) is invalid, valid formats are %s",
+			p.config.Format, strings.Join(ValidFormats, ", ")))
+	}
+
+	if p.config.ExportTimeout < 0 {
+		errs = packersdk.MultiErrorAppend(errs, fmt.Errorf("specified export_timeout(%s) is invalid", p.config.ExportTimeout))
+	}
+
+	if errs != nil && len(errs.Errors) > 0 {
+		return errs
+	}
+
+	packersdk.LogSecretFilter.Set(p.config.SecretId, p.config.SecretKey)
+
+	return nil
+}
+
+func (p *PostProcessor) PostProcess(ctx context.Context, ui packersdk.Ui, artifact packersdk.Artifact) (packersdk.Artifact, bool, bool, error) {
+	accepted := false
+	for _, id := range acceptedBuilderIds {
+		if artifact.BuilderId() == id {
+			accepted = true
+		}
+	}
+	if !accepted {
+		return nil, false, false, fmt.Errorf("Unknown artifact type: %s\nCan only export from tencentcloud image artifacts.",
+			artifact.BuilderId())
+	}
+
+	// The artifact may come from another plugin process, so the images are
+	// read from its id rather than its type.
+	imageId, err := regionImage(artifact.Id(), p.config.Region)
+	if err != nil {
+		return nil, false, false, err
+	}
+
+	cvmClient, err := builder.NewCvmClient(&p.config.TencentCloudAccessConfig)
+	if err != nil {
+		return nil, false, false, err
+	}
+
+	cosClient, err := builder.NewCosClient(&p.config.TencentCloudAccessConfig, p.config.CosBucket, p.config.CosEndpoint)
+	if err != nil {
+		return nil, false, false, err
+	}
+
+	prefix := p.config.CosKeyPrefix
+	if prefix == "" {
+		prefix = "packer-export-" + imageId
+	}
+
+	req := cvm.NewExportImagesRequest()
+	req.BucketName = &p.config.CosBucket
+	req.ImageIds = []*string{&imageId}
+	req.ExportFormat = &p.config.Format
+	req.FileNamePrefixList = []*string{&prefix}
+	req.OnlyExportRootDisk = &p.config.OnlyExportRootDisk
+	if p.config.RoleName != "" {
+		req.RoleName = &p.config.RoleName
+	}
+
+	ui.Say(fmt.Sprintf("Exporting image %s to cos bucket %s", imageId, p.config.CosBucket))
+	var resp *cvm.ExportImagesResponse
+	err = builder.Retry(ctx, func(ctx context.Context) error {
+		var e error
+		resp, e = cvmClient.ExportImages(req)
+		return e
+	})
+	if err != nil {
+		return nil, false, false, fmt.Errorf("Failed to export image: %s", err)
+	}
+
+	keys := make([]string, 0, len(resp.Response.CosPaths))
+	for _, cosPath := range resp.Response.CosPaths {
+		keys = append(keys, objectKey(*cosPath))
+	}
+
+	ui.Message("Waiting for export finished")
+	if err := waitForExport(ctx, cvmClient, cosClient, imageId, keys, p.config.ExportTimeout); err != nil {
+		return nil, false, false, fmt.Errorf("Failed to wait for export finished: %s", err)
+	}
+	for _, key := range keys {
+		ui.Message(fmt.Sprintf("Image exported as %s", key))
+	}
+
+	result := &Artifact{
+		ImageId:   imageId,
+		CosBucket: p.config.CosBucket,
+		CosKeys:   keys,
+		Client:    cosClient,
+	}
+
+	if p.config.OutputDirectory != "" {
+		if err := os.MkdirAll(p.config.OutputDirectory, 0755); err != nil {
+			return nil, false, false, err
+		}
+		for _, key := range keys {
+			file := filepath.Join(p.config.OutputDirectory, path.Base(key))
+			ui.Say(fmt.Sprintf("Downloading %s to %s", key, file))
+			if _, err := cosClient.Object.GetToFile(ctx, key, file, nil); err != nil {
+				return nil, false, false, fmt.Errorf("Failed to download %s: %s", key, err)
+			}
+			result.files = append(result.files, file)
+		}
+	}
+
+	// The exported image is kept, it is still the input artifact.
+	return result, true, false, nil
+}
+
+// regionImage returns the image of the region from an artifact id, a list
+// of region:image pairs.
+func regionImage(artifactId string, region string) (string, error) {
+	for _, part := range strings.Split(artifactId, ",") {
+		if r, imageId, ok := strings.Cut(part, ":"); ok && r == region {
+			return imageId, nil
+		}
+	}
+
+	return "", fmt.Errorf("No image in region %s found in artifact %s", region, artifactId)
+}
+
+// objectKey returns the key of an exported object, which may be given as
+// an url or with the bucket name.
+func objectKey(cosPath string) string {
+	if u, err := url.Parse(cosPath); err == nil && u.Scheme != "" {
+		cosPath = u.Path
+	}
+
+	return strings.TrimPrefix(cosPath, "/")
+}
+
+// waitForExport waits for the image not to be exporting anymore and all
+// its objects to exist.
+func waitForExport(ctx context.Context, cvmClient *cvm.Client, cosClient *cos.Client, imageId string,
+	keys []string, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	req := cvm.NewDescribeImagesRequest()
+	req.ImageIds = []*string{&imageId}
+	for {
+		images, err := builder.GetImages(ctx, cvmClient, req)
+		if err != nil {
+			return err
+		}
+		if len(images) == 0 {
+			return fmt.Errorf("image(%s) not exist", imageId)
+		}
+
+		state := *images[0].ImageState
+		if strings.HasSuffix(state, "FAILED") {
+			return fmt.Errorf("image(%s) status is %s", imageId, state)
+		}
+		if state == "NORMAL" && objectsExist(ctx, cosClient, keys) {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("wait image(%s) export timeout", imageId)
+		case <-time.After(builder.DefaultWaitForInterval * time.Second):
+		}
+	}
+}
+
+func objectsExist(ctx context.Context, client *cos.Client, keys []string) bool {
+	for _, key := range keys {
+		if ok, err := client.Object.IsExist(ctx, key); err != nil || !ok {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,151 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package tencentcloudexport
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
+
+	builder "github.com/hashicorp/packer-plugin-tencentcloud/builder/tencentcloud/cvm"
+)
+
+func testConfig() map[string]interface{} {
+	return map[string]interface{}{
+		"secret_id":  "secret-id",
+		"secret_key": "secret-key",
+		"region":     "ap-guangzhou",
+		"cos_bucket": "packer-1250000000",
+	}
+}
+
+func TestPostProcessor_Configure(t *testing.T) {
+	var p PostProcessor
+	if err := p.Configure(testConfig()); err != nil {
+		t.Fatalf("shouldn't have err: %v", err)
+	}
+	if p.config.Format != "qcow2" {
+		t.Fatalf("invalid default format: %s", p.config.Format)
+	}
+
+	cases := map[string]interface{}{
+		"cos_bucket":     "",
+		"format":         "iso",
+		"export_timeout": "-1m",
+	}
+	for key, value := range cases {
+		var p PostProcessor
+		config := testConfig()
+		config[key] = value
+		if err := p.Configure(config); err == nil {
+			t.Fatalf("should have err with %s: %v", key, value)
+		}
+	}
+}
+
+func TestRegionImage(t *testing.T) {
+	imageId, err := regionImage("ap-beijing:img-asdf1234,ap-guangzhou:img-qwer1234", "ap-guangzhou")
+	if err != nil || imageId != "img-qwer1234" {
+		t.Fatalf("invalid image: %s, %v", imageId, err)
+	}
+
+	if _, err := regionImage("ap-beijing:img-asdf1234", "ap-guangzhou"); err == nil {
+		t.Fatal("should have err")
+	}
+}
+
+func TestObjectKey(t *testing.T) {
+	cases := map[string]string{
+		"packer-export-img-qwer1234.qcow2":                                         "packer-export-img-qwer1234.qcow2",
+		"/packer-export-img-qwer1234.qcow2":                                        "packer-export-img-qwer1234.qcow2",
+		"https://packer-1250000000.cos.ap-guangzhou.myqcloud.com/export/img.qcow2": "export/img.qcow2",
+	}
+	for cosPath, key := range cases {
+		if k := objectKey(cosPath); k != key {
+			t.Fatalf("invalid key of %s: %s", cosPath, k)
+		}
+	}
+}
+
+func TestPostProcessor_PostProcess(t *testing.T) {
+	cosServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/packer-export-img-qwer1234.qcow2" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte("qcow2 disk"))
+	}))
+	defer cosServer.Close()
+
+	var exported map[string]interface{}
+	cvmServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var response interface{}
+		switch action := r.Header.Get("X-TC-Action"); action {
+		case "ExportImages":
+			_ = json.NewDecoder(r.Body).Decode(&exported)
+			response = map[string]interface{}{
+				"TaskId":   1,
+				"CosPaths": []string{"packer-export-img-qwer1234.qcow2"},
+			}
+		case "DescribeImages":
+			response = map[string]interface{}{
+				"ImageSet": []map[string]string{
+					{"ImageId": "img-qwer1234", "ImageName": "packer", "ImageState": "NORMAL"},
+				},
+				"TotalCount": 1,
+			}
+		default:
+			http.Error(w, "unknown action "+action, http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"Response": response})
+	}))
+	defer cvmServer.Close()
+
+	output := t.TempDir()
+	config := testConfig()
+	config["cos_endpoint"] = cosServer.URL
+	config["cvm_endpoint"] = cvmServer.URL
+	config["vpc_endpoint"] = cvmServer.URL
+	config["output_directory"] = output
+	var p PostProcessor
+	if err := p.Configure(config); err != nil {
+		t.Fatalf("shouldn't have err: %v", err)
+	}
+
+	artifact := &builder.Artifact{
+		TencentCloudImages: map[string]string{"ap-guangzhou": "img-qwer1234", "ap-beijing": "img-asdf1234"},
+		BuilderIdValue:     builder.BuilderId,
+	}
+	result, keep, _, err := p.PostProcess(context.Background(), packersdk.TestUi(t), artifact)
+	if err != nil {
+		t.Fatalf("shouldn't have err: %v", err)
+	}
+	if !keep {
+		t.Fatal("exported image should be kept")
+	}
+
+	if exported["ExportFormat"] != "qcow2" || !strings.Contains(exported["ImageIds"].([]interface{})[0].(string), "img-qwer1234") {
+		t.Fatalf("invalid export request: %v", exported)
+	}
+	if result.Id() != "packer-export-img-qwer1234.qcow2" {
+		t.Fatalf("invalid artifact id: %s", result.Id())
+	}
+
+	file := filepath.Join(output, "packer-export-img-qwer1234.qcow2")
+	if files := result.Files(); len(files) != 1 || files[0] != file {
+		t.Fatalf("invalid artifact files: %v", files)
+	}
+	if content, err := os.ReadFile(file); err != nil || string(content) != "qcow2 disk" {
+		t.Fatalf("invalid downloaded file: %q, %v", content, err)
+	}
+}
@@ -0,0 +1,274 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+//go:generate packer-sdc struct-markdown
+//go:generate packer-sdc mapstructure-to-hcl2 -type Config
+
+// Package tencentcloudimport imports disk images built locally as
+// TencentCloud custom images, through a cos bucket.
+package tencentcloudimport
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/hashicorp/hcl/v2/hcldec"
+	"github.com/hashicorp/packer-plugin-sdk/common"
+	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
+	"github.com/hashicorp/packer-plugin-sdk/template/config"
+	"github.com/hashicorp/packer-plugin-sdk/template/interpolate"
+	cvm "github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/cvm/v20170312"
+
+	builder "github.com/hashicorp/packer-plugin-tencentcloud/builder/tencentcloud/cvm"
+)
+
+const BuilderId = "packer.post-processor.tencentcloud-import"
+
+// ValidFormats are the disk image formats ImportImage accepts.
+var ValidFormats = []string{"qcow2", "vhd", "vmdk", "raw"}
+
+type Config struct {
+	common.PackerConfig              `mapstructure:",squash"`
+	builder.TencentCloudAccessConfig `mapstructure:",squash"`
+
+	// The name of the cos bucket the disk image is uploaded to, in the
+	// form `<name>-<appid>`. It must be in the same region as the image.
+	CosBucket string `mapstructure:"cos_bucket" required:"true"`
+	// The key of the object the disk image is uploaded as, default is
+	// `packer-import-<image_name>.<format>`.
+	CosKey string `mapstructure:"cos_key" required:"false"`
+	// The url of the cos bucket, default is
+	// `https://<cos_bucket>.cos.<region>.myqcloud.com`.
+	CosEndpoint string `mapstructure:"cos_endpoint" required:"false"`
+	// Do not delete the uploaded object once the image is imported.
+	SkipClean bool `mapstructure:"skip_clean" required:"false"`
+	// The name of the imported image, which must not be used yet.
+	ImageName string `mapstructure:"image_name" required:"true"`
+	// The description of the imported image.
+	ImageDescription string `mapstructure:"image_description" required:"false"`
+	// Key/value pair tags that will be applied to the imported image.
+	ImageTags map[string]string `mapstructure:"image_tags" required:"false"`
+	// The os type of the disk image, such as `CentOS` or `Ubuntu`.
+	OsType string `mapstructure:"os_type" required:"true"`
+	// The os version of the disk image, such as `7` or `20.04`.
+	OsVersion string `mapstructure:"os_version" required:"true"`
+	// The architecture of the disk image, `x86_64` or `i386`, default is
+	// `x86_64`.
+	Architecture string `mapstructure:"architecture" required:"false"`
+	// The format of the disk image, one of `qcow2`, `vhd`, `vmdk` or `raw`.
+	// The first file of the artifact with this extension is imported,
+	// default is the first file with a supported extension. If set and the
+	// artifact has a single file, it is imported whatever its extension.
+	Format string `mapstructure:"format" required:"false"`
+	// Skip the checks of the disk image, which may make the image unable
+	// to boot.
+	Force bool `mapstructure:"force" required:"false"`
+
+	ctx interpolate.Context
+}
+
+type PostProcessor struct {
+	config Config
+}
+
+func (p *PostProcessor) ConfigSpec() hcldec.ObjectSpec { return p.config.FlatMapstructure().HCL2Spec() }
+
+func (p *PostProcessor) Configure(raws ...interface{}) error {
+	err := config.Decode(&p.config, &config.DecodeOpts{
+		PluginType:         BuilderId,
+		Interpolate:        true,
+		InterpolateContext: &p.config.ctx,
+	}, raws...)
+	if err != nil {
+		return err
+	}
+
+	if p.config.Architecture == "" {
+		p.config.Architecture = "x86_64"
+	}
+
+	// Accumulate any errors
+	var errs *packersdk.MultiError
+	errs = packersdk.MultiErrorAppend(errs, p.config.TencentCloudAccessConfig.Prepare(&p.config.ctx)...)
+
+	if p.config.CosBucket == "" {
+		errs = packersdk.MultiErrorAppend(errs, errors.New("cos_bucket must be specified"))
+	}
+
+	if p.config.ImageName == "" {
+		errs = packersdk.MultiErrorAppend(errs, errors.New("image_name must be specified"))
+	} else if utf8.RuneCountInString(p.config.ImageName) > 60 {
+		errs = packersdk.MultiErrorAppend(errs, errors.New("image_name length should not exceed 60 characters"))
+	}
+
+	if utf8.RuneCountInString(p.config.ImageDescription) > 60 {
+		errs = packersdk.MultiErrorAppend(errs, errors.New("image_description length should not exceed 60 characters"))
+	}
+
+	if p.config.OsType == "" {
+		errs = packersdk.MultiErrorAppend(errs, errors.New("os_type must be specified"))
+	}
+
+	if p.config.OsVersion == "" {
+		errs = packersdk.MultiErrorAppend(errs, errors.New("os_version must be specified"))
+	}
+
+	if p.config.Architecture != "x86_64" && p.config.Architecture != "i386" {
+		errs = packersdk.MultiErrorAppend(errs, fmt.Errorf("specified architecture(%s) is invalid", p.config.Architecture))
+	}
+
+	if p.config.Format != "" && !validFormat(p.config.Format) {
+		errs = packersdk.MultiErrorAppend(errs, fmt.Errorf("specified format(%s) is invalid, valid formats are %s",
+			p.config.Format, strings.Join(ValidFormats, ", ")))
+	}
+
+	if errs != nil && len(errs.Errors) > 0 {
+		return errs
+	}
+
+	packersdk.LogSecretFilter.Set(p.config.SecretId, p.config.SecretKey)
+
+	return nil
+}
+
+func (p *PostProcessor) PostProcess(ctx context.Context, ui packersdk.Ui, artifact packersdk.Artifact) (packersdk.Artifact, bool, bool, error) {
+	source, format, err := selectFile(artifact.Files(), p.config.Format)
+	if err != nil {
+		return nil, false, false, err
+	}
+
+	cvmClient, err := builder.NewCvmClient(&p.config.TencentCloudAccessConfig)
+	if err != nil {
+		return nil, false, false, err
+	}
+
+	cosClient, err := builder.NewCosClient(&p.config.TencentCloudAccessConfig, p.config.CosBucket, p.config.CosEndpoint)
+	if err != nil {
+		return nil, false, false, err
+	}
+
+	image, err := builder.GetImageByName(ctx, cvmClient, p.config.ImageName)
+	if err != nil {
+		return nil, false, false, fmt.Errorf("Failed to get images info: %s", err)
+	}
+	if image != nil {
+		return nil, false, false, fmt.Errorf("Image name %s has exists", p.config.ImageName)
+	}
+
+	key := p.config.CosKey
+	if key == "" {
+		key = fmt.Sprintf("packer-import-%s.%s", p.config.ImageName, format)
+	}
+
+	ui.Say(fmt.Sprintf("Uploading %s to cos bucket %s as %s", source, p.config.CosBucket, key))
+	if _, _, err := cosClient.Object.Upload(ctx, key, source, nil); err != nil {
+		return nil, false, false, fmt.Errorf("Failed to upload %s: %s", source, err)
+	}
+
+	if !p.config.SkipClean {
+		defer func() {
+			ui.Say(fmt.Sprintf("Deleting %s from cos bucket %s", key, p.config.CosBucket))
+			if _, err := cosClient.Object.Delete(context.TODO(), key); err != nil {
+				ui.Error(fmt.Sprintf("Failed to delete %s from cos bucket %s, please delete it manually: %s",
+					key, p.config.CosBucket, err))
+			}
+		}()
+	}
+
+	imageUrl := cosClient.Object.GetObjectURL(key).String()
+	log.Printf("[DEBUG] Importing image from %s", imageUrl)
+
+	req := cvm.NewImportImageRequest()
+	req.ImageUrl = &imageUrl
+	req.ImageName = &p.config.ImageName
+	req.ImageDescription = &p.config.ImageDescription
+	req.OsType = &p.config.OsType
+	req.OsVersion = &p.config.OsVersion
+	req.Architecture = &p.config.Architecture
+	req.Force = &p.config.Force
+
+	var tags []*cvm.Tag
+	for k, v := range p.config.ImageTags {
+		k := k
+		v := v
+		tags = append(tags, &cvm.Tag{
+			Key:   &k,
+			Value: &v,
+		})
+	}
+
+	resourceType := "image"
+	if len(tags) > 0 {
+		req.TagSpecification = []*cvm.TagSpecification{
+			{
+				ResourceType: &resourceType,
+				Tags:         tags,
+			},
+		}
+	}
+
+	ui.Say(fmt.Sprintf("Importing image %s", p.config.ImageName))
+	err = builder.Retry(ctx, func(ctx context.Context) error {
+		_, e := cvmClient.ImportImage(req)
+		return e
+	})
+	if err != nil {
+		return nil, false, false, fmt.Errorf("Failed to import image: %s", err)
+	}
+
+	ui.Message("Waiting for image ready")
+	if err := builder.WaitForImageReady(ctx, cvmClient, p.config.ImageName, "NORMAL", 3600); err != nil {
+		return nil, false, false, fmt.Errorf("Failed to wait for image ready: %s", err)
+	}
+
+	image, err = builder.GetImageByName(ctx, cvmClient, p.config.ImageName)
+	if err != nil {
+		return nil, false, false, fmt.Errorf("Failed to get image: %s", err)
+	}
+	if image == nil {
+		return nil, false, false, fmt.Errorf("No image return")
+	}
+	ui.Message(fmt.Sprintf("Image imported: %s", *image.ImageId))
+
+	return &builder.Artifact{
+		TencentCloudImages: map[string]string{p.config.Region: *image.ImageId},
+		BuilderIdValue:     BuilderId,
+		Client:             cvmClient,
+		AccessConfig:       &p.config.TencentCloudAccessConfig,
+		StateData: map[string]interface{}{
+			"image_tags": p.config.ImageTags,
+		},
+	}, false, false, nil
+}
+
+// selectFile returns the file of the artifact to import, with its format.
+func selectFile(files []string, format string) (string, string, error) {
+	for _, file := range files {
+		ext := strings.ToLower(strings.TrimPrefix(filepath.Ext(file), "."))
+		if (format == "" && validFormat(ext)) || (format != "" && ext == format) {
+			return file, ext, nil
+		}
+	}
+
+	if format != "" && len(files) == 1 {
+		return files[0], format, nil
+	}
+
+	return "", "", fmt.Errorf("No file with a supported format(%s) found in artifact files %v",
+		strings.Join(ValidFormats, ", "), files)
+}
+
+func validFormat(format string) bool {
+	for _, valid := range ValidFormats {
+		if format == valid {
+			return true
+		}
+	}
+
+	return false
+}
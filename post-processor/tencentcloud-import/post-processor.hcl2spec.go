@@ -0,0 +1,100 @@
+// Code generated by "packer-sdc mapstructure-to-hcl2"; DO NOT EDIT.
+
+package tencentcloudimport
+
+import (
+	"github.com/hashicorp/hcl/v2/hcldec"
+	"github.com/hashicorp/packer-plugin-tencentcloud/builder/tencentcloud/cvm"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// FlatConfig is an auto-generated flat version of Config.
+// Where the contents of a field with a `mapstructure:,squash` tag are bubbled up.
+type FlatConfig struct {
+	PackerBuildName      *string                         `mapstructure:"packer_build_name" cty:"packer_build_name" hcl:"packer_build_name"`
+	PackerBuilderType    *string                         `mapstructure:"packer_builder_type" cty:"packer_builder_type" hcl:"packer_builder_type"`
+	PackerCoreVersion    *string                         `mapstructure:"packer_core_version" cty:"packer_core_version" hcl:"packer_core_version"`
+	PackerDebug          *bool                           `mapstructure:"packer_debug" cty:"packer_debug" hcl:"packer_debug"`
+	PackerForce          *bool                           `mapstructure:"packer_force" cty:"packer_force" hcl:"packer_force"`
+	PackerOnError        *string                         `mapstructure:"packer_on_error" cty:"packer_on_error" hcl:"packer_on_error"`
+	PackerUserVars       map[string]string               `mapstructure:"packer_user_variables" cty:"packer_user_variables" hcl:"packer_user_variables"`
+	PackerSensitiveVars  []string                        `mapstructure:"packer_sensitive_variables" cty:"packer_sensitive_variables" hcl:"packer_sensitive_variables"`
+	SecretId             *string                         `mapstructure:"secret_id" required:"true" cty:"secret_id" hcl:"secret_id"`
+	SecretKey            *string                         `mapstructure:"secret_key" required:"true" cty:"secret_key" hcl:"secret_key"`
+	Region               *string                         `mapstructure:"region" required:"true" cty:"region" hcl:"region"`
+	Zone                 *string                         `mapstructure:"zone" required:"false" cty:"zone" hcl:"zone"`
+	Zones                []string                        `mapstructure:"zones" required:"false" cty:"zones" hcl:"zones"`
+	CvmEndpoint          *string                         `mapstructure:"cvm_endpoint" required:"false" cty:"cvm_endpoint" hcl:"cvm_endpoint"`
+	VpcEndpoint          *string                         `mapstructure:"vpc_endpoint" required:"false" cty:"vpc_endpoint" hcl:"vpc_endpoint"`
+	TatEndpoint          *string                         `mapstructure:"tat_endpoint" required:"false" cty:"tat_endpoint" hcl:"tat_endpoint"`
+	CbsEndpoint          *string                         `mapstructure:"cbs_endpoint" required:"false" cty:"cbs_endpoint" hcl:"cbs_endpoint"`
+	TagEndpoint          *string                         `mapstructure:"tag_endpoint" required:"false" cty:"tag_endpoint" hcl:"tag_endpoint"`
+	StsEndpoint          *string                         `mapstructure:"sts_endpoint" required:"false" cty:"sts_endpoint" hcl:"sts_endpoint"`
+	SecurityToken        *string                         `mapstructure:"security_token" required:"false" cty:"security_token" hcl:"security_token"`
+	AssumeRole           *cvm.FlatTencentCloudAccessRole `mapstructure:"assume_role" required:"false" cty:"assume_role" hcl:"assume_role"`
+	Profile              *string                         `mapstructure:"profile" required:"false" cty:"profile" hcl:"profile"`
+	SharedCredentialsDir *string                         `mapstructure:"shared_credentials_dir" required:"false" cty:"shared_credentials_dir" hcl:"shared_credentials_dir"`
+	CosBucket            *string                         `mapstructure:"cos_bucket" required:"true" cty:"cos_bucket" hcl:"cos_bucket"`
+	CosKey               *string                         `mapstructure:"cos_key" required:"false" cty:"cos_key" hcl:"cos_key"`
+	CosEndpoint          *string                         `mapstructure:"cos_endpoint" required:"false" cty:"cos_endpoint" hcl:"cos_endpoint"`
+	SkipClean            *bool                           `mapstructure:"skip_clean" required:"false" cty:"skip_clean" hcl:"skip_clean"`
+	ImageName            *string                         `mapstructure:"image_name" required:"true" cty:"image_name" hcl:"image_name"`
+	ImageDescription     *string                         `mapstructure:"image_description" required:"false" cty:"image_description" hcl:"image_description"`
+	ImageTags            map[string]string               `mapstructure:"image_tags" required:"false" cty:"image_tags" hcl:"image_tags"`
+	OsType               *string                         `mapstructure:"os_type" required:"true" cty:"os_type" hcl:"os_type"`
+	OsVersion            *string                         `mapstructure:"os_version" required:"true" cty:"os_version" hcl:"os_version"`
+	Architecture         *string                         `mapstructure:"architecture" required:"false" cty:"architecture" hcl:"architecture"`
+	Format               *string                         `mapstructure:"format" required:"false" cty:"format" hcl:"format"`
+	Force                *bool                           `mapstructure:"force" required:"false" cty:"force" hcl:"force"`
+}
+
+// FlatMapstructure returns a new FlatConfig.
+// FlatConfig is an auto-generated flat version of Config.
+// Where the contents a fields with a `mapstructure:,squash` tag are bubbled up.
+func (*Config) FlatMapstructure() interface{ HCL2Spec() map[string]hcldec.Spec } {
+	return new(FlatConfig)
+}
+
+// HCL2Spec returns the hcl spec of a Config.
+// This spec is used by HCL to read the fields of Config.
+// The decoded values from this spec will then be applied to a FlatConfig.
+func (*FlatConfig) HCL2Spec() map[string]hcldec.Spec {
+	s := map[string]hcldec.Spec{
+		"packer_build_name":          &hcldec.AttrSpec{Name: "packer_build_name", Type: cty.String, Required: false},
+		"packer_builder_type":        &hcldec.AttrSpec{Name: "packer_builder_type", Type: cty.String, Required: false},
+		"packer_core_version":        &hcldec.AttrSpec{Name: "packer_core_version", Type: cty.String, Required: false},
+		"packer_debug":               &hcldec.AttrSpec{Name: "packer_debug", Type: cty.Bool, Required: false},
+		"packer_force":               &hcldec.AttrSpec{Name: "packer_force", Type: cty.Bool, Required: false},
+		"packer_on_error":            &hcldec.AttrSpec{Name: "packer_on_error", Type: cty.String, Required: false},
+		"packer_user_variables":      &hcldec.AttrSpec{Name: "packer_user_variables", Type: cty.Map(cty.String), Required: false},
+		"packer_sensitive_variables": &hcldec.AttrSpec{Name: "packer_sensitive_variables", Type: cty.List(cty.String), Required: false},
+		"secret_id":                  &hcldec.AttrSpec{Name: "secret_id", Type: cty.String, Required: false},
+		"secret_key":                 &hcldec.AttrSpec{Name: "secret_key", Type: cty.String, Required: false},
+		"region":                     &hcldec.AttrSpec{Name: "region", Type: cty.String, Required: false},
+		"zone":                       &hcldec.AttrSpec{Name: "zone", Type: cty.String, Required: false},
+		"zones":                      &hcldec.AttrSpec{Name: "zones", Type: cty.List(cty.String), Required: false},
+		"cvm_endpoint":               &hcldec.AttrSpec{Name: "cvm_endpoint", Type: cty.String, Required: false},
+		"vpc_endpoint":               &hcldec.AttrSpec{Name: "vpc_endpoint", Type: cty.String, Required: false},
+		"tat_endpoint":               &hcldec.AttrSpec{Name: "tat_endpoint", Type: cty.String, Required: false},
+		"cbs_endpoint":               &hcldec.AttrSpec{Name: "cbs_endpoint", Type: cty.String, Required: false},
+		"tag_endpoint":               &hcldec.AttrSpec{Name: "tag_endpoint", Type: cty.String, Required: false},
+		"sts_endpoint":               &hcldec.AttrSpec{Name: "sts_endpoint", Type: cty.String, Required: false},
+		"security_token":             &hcldec.AttrSpec{Name: "security_token", Type: cty.String, Required: false},
+		"assume_role":                &hcldec.BlockSpec{TypeName: "assume_role", Nested: hcldec.ObjectSpec((*cvm.FlatTencentCloudAccessRole)(nil).HCL2Spec())},
+		"profile":                    &hcldec.AttrSpec{Name: "profile", Type: cty.String, Required: false},
+		"shared_credentials_dir":     &hcldec.AttrSpec{Name: "shared_credentials_dir", Type: cty.String, Required: false},
+		"cos_bucket":                 &hcldec.AttrSpec{Name: "cos_bucket", Type: cty.String, Required: false},
+		"cos_key":                    &hcldec.AttrSpec{Name: "cos_key", Type: cty.String, Required: false},
+		"cos_endpoint":               &hcldec.AttrSpec{Name: "cos_endpoint", Type: cty.String, Required: false},
+		"skip_clean":                 &hcldec.AttrSpec{Name: "skip_clean", Type: cty.Bool, Required: false},
+		"image_name":                 &hcldec.AttrSpec{Name: "image_name", Type: cty.String, Required: false},
+		"image_description":          &hcldec.AttrSpec{Name: "image_description", Type: cty.String, Required: false},
+		"image_tags":                 &hcldec.AttrSpec{Name: "image_tags", Type: cty.Map(cty.String), Required: false},
+		"os_type":                    &hcldec.AttrSpec{Name: "os_type", Type: cty.String, Required: false},
+		"os_version":                 &hcldec.AttrSpec{Name: "os_version", Type: cty.String, Required: false},
+		"architecture":               &hcldec.AttrSpec{Name: "architecture", Type: cty.String, Required: false},
+		"format":                     &hcldec.AttrSpec{Name: "format", Type: cty.String, Required: false},
+		"force":                      &hcldec.AttrSpec{Name: "force", Type: cty.Bool, Required: false},
+	}
+	return s
+}
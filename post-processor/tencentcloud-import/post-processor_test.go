@@ -0,0 +1,190 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package tencentcloudimport
+
+import (
+	"context"
+	"encoding/json"
+	"hash/crc64"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
+)
+
+func testConfig() map[string]interface{} {
+	return map[string]interface{}{
+		"secret_id":  "secret-id",
+		"secret_key": "secret-key",
+		"region":     "ap-guangzhou",
+		"cos_bucket": "packer-1250000000",
+		"image_name": "packer-import",
+		"os_type":    "CentOS",
+		"os_version": "7",
+	}
+}
+
+func TestPostProcessor_Configure(t *testing.T) {
+	var p PostProcessor
+	if err := p.Configure(testConfig()); err != nil {
+		t.Fatalf("shouldn't have err: %v", err)
+	}
+	if p.config.Architecture != "x86_64" {
+		t.Fatalf("invalid default architecture: %s", p.config.Architecture)
+	}
+
+	cases := map[string]interface{}{
+		"cos_bucket":   "",
+		"image_name":   "",
+		"os_type":      "",
+		"os_version":   "",
+		"architecture": "arm",
+		"format":       "iso",
+	}
+	for key, value := range cases {
+		var p PostProcessor
+		config := testConfig()
+		config[key] = value
+		if err := p.Configure(config); err == nil {
+			t.Fatalf("should have err with %s: %v", key, value)
+		}
+	}
+}
+
+func TestSelectFile(t *testing.T) {
+	cases := []struct {
+		files  []string
+		format string
+		file   string
+		ok     bool
+	}{
+		{[]string{"disk.ovf", "disk.vmdk"}, "", "disk.vmdk", true},
+		{[]string{"disk.QCOW2"}, "", "disk.QCOW2", true},
+		{[]string{"disk.raw", "disk.qcow2"}, "qcow2", "disk.qcow2", true},
+		{[]string{"packer-centos"}, "qcow2", "packer-centos", true},
+		{[]string{"packer-centos"}, "", "", false},
+		{[]string{"a", "b"}, "raw", "", false},
+	}
+
+	for _, c := range cases {
+		file, _, err := selectFile(c.files, c.format)
+		if (err == nil) != c.ok || file != c.file {
+			t.Fatalf("selectFile(%v, %q) = %q, %v", c.files, c.format, file, err)
+		}
+	}
+}
+
+// fakeCos stores the objects put in memory.
+type fakeCos struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+func (f *fakeCos) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	key := strings.TrimPrefix(r.URL.Path, "/")
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch r.Method {
+	case http.MethodPut:
+		body, _ := io.ReadAll(r.Body)
+		f.objects[key] = body
+		crc := crc64.Checksum(body, crc64.MakeTable(crc64.ECMA))
+		w.Header().Set("x-cos-hash-crc64ecma", strconv.FormatUint(crc, 10))
+	case http.MethodDelete:
+		delete(f.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// fakeCvm imports images immediately, remembering the request.
+type fakeCvm struct {
+	mu       sync.Mutex
+	imported map[string]interface{}
+}
+
+func (f *fakeCvm) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var response interface{}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch action := r.Header.Get("X-TC-Action"); action {
+	case "ImportImage":
+		if err := json.NewDecoder(r.Body).Decode(&f.imported); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		response = map[string]interface{}{}
+	case "DescribeImages":
+		images := []map[string]string{}
+		if f.imported != nil {
+			images = append(images, map[string]string{
+				"ImageId":    "img-qwer1234",
+				"ImageName":  f.imported["ImageName"].(string),
+				"ImageState": "NORMAL",
+			})
+		}
+		response = map[string]interface{}{"ImageSet": images, "TotalCount": len(images)}
+	default:
+		http.Error(w, "unknown action "+action, http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"Response": response})
+}
+
+func TestPostProcessor_PostProcess(t *testing.T) {
+	cos := &fakeCos{objects: make(map[string][]byte)}
+	cosServer := httptest.NewServer(cos)
+	defer cosServer.Close()
+
+	cvm := &fakeCvm{}
+	cvmServer := httptest.NewServer(cvm)
+	defer cvmServer.Close()
+
+	disk := filepath.Join(t.TempDir(), "disk.qcow2")
+	if err := os.WriteFile(disk, []byte("qcow2 disk"), 0644); err != nil {
+		t.Fatalf("shouldn't have err: %v", err)
+	}
+
+	config := testConfig()
+	config["cos_endpoint"] = cosServer.URL
+	config["cvm_endpoint"] = cvmServer.URL
+	config["vpc_endpoint"] = cvmServer.URL
+	var p PostProcessor
+	if err := p.Configure(config); err != nil {
+		t.Fatalf("shouldn't have err: %v", err)
+	}
+
+	artifact := &packersdk.MockArtifact{FilesValue: []string{disk}}
+	result, _, _, err := p.PostProcess(context.Background(), packersdk.TestUi(t), artifact)
+	if err != nil {
+		t.Fatalf("shouldn't have err: %v", err)
+	}
+
+	if result.Id() != "ap-guangzhou:img-qwer1234" {
+		t.Fatalf("invalid artifact id: %s", result.Id())
+	}
+	if url := cvm.imported["ImageUrl"]; url != cosServer.URL+"/packer-import-packer-import.qcow2" {
+		t.Fatalf("invalid image url: %v", url)
+	}
+	if cvm.imported["OsType"] != "CentOS" || cvm.imported["OsVersion"] != "7" {
+		t.Fatalf("invalid os: %v", cvm.imported)
+	}
+	if len(cos.objects) != 0 {
+		t.Fatalf("uploaded object should be deleted: %v", cos.objects)
+	}
+}